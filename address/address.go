@@ -0,0 +1,345 @@
+// Package address provides methods for parsing and normalizing free-text (street) addresses without
+// depending on external libraries or services (like libpostal). It is heuristic rather than exhaustive
+// and is tuned towards the (mostly North American) address formats found in the data sources supported
+// by this package.
+package address
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Address defines the structured components derived from parsing a free-text address.
+type Address struct {
+	// The house (or building) number of the address
+	HouseNumber string `json:"house_number,omitempty"`
+	// The name of the street of the address
+	Street string `json:"street,omitempty"`
+	// The unit, suite or apartment designator of the address
+	Unit string `json:"unit,omitempty"`
+	// The locality (city or town) of the address
+	Locality string `json:"locality,omitempty"`
+	// The region (state or province) of the address
+	Region string `json:"region,omitempty"`
+	// The postal code of the address
+	Postcode string `json:"postcode,omitempty"`
+	// The country of the address
+	Country string `json:"country,omitempty"`
+}
+
+var re_housenumber = regexp.MustCompile(`^\d+[a-z]?(?:-\d+[a-z]?)?$`)
+var re_number = regexp.MustCompile(`^\d+$`)
+var re_ordinal = regexp.MustCompile(`^\d+(?:st|nd|rd|th)$`)
+var re_postcode_us = regexp.MustCompile(`^\d{5}(?:-\d{4})?$`)
+var re_postcode_ca = regexp.MustCompile(`^[a-z]\d[a-z]\d[a-z]\d$`)
+var re_postcode_ca_fsa = regexp.MustCompile(`^[a-z]\d[a-z]$`)
+var re_postcode_ca_ldu = regexp.MustCompile(`^\d[a-z]\d$`)
+var re_region = regexp.MustCompile(`^[a-z]{2}$`)
+
+// token is an individual word in an address string.
+type token struct {
+	// The token as it was written in the original address, minus any trailing commas
+	raw string
+	// The lower-cased token with any surrounding punctuation removed
+	key string
+	// A boolean flag indicating whether the token was followed by a comma
+	comma bool
+	// A boolean flag indicating whether the token (minus any trailing comma) ended with a period
+	period bool
+}
+
+func tokenize(raw string) []*token {
+
+	tokens := make([]*token, 0)
+
+	for _, w := range strings.Fields(raw) {
+
+		comma := strings.HasSuffix(w, ",")
+		w = strings.TrimRight(w, ",")
+
+		period := strings.HasSuffix(w, ".")
+
+		key := strings.ToLower(w)
+		key = strings.Trim(key, ".,;:()\"'")
+
+		if key == "" && w != "#" {
+
+			if comma && len(tokens) > 0 {
+				tokens[len(tokens)-1].comma = true
+			}
+
+			continue
+		}
+
+		if w == "#" {
+			key = "#"
+		}
+
+		t := &token{
+			raw:    w,
+			key:    key,
+			comma:  comma,
+			period: period,
+		}
+
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}
+
+func joinTokens(tokens []*token) string {
+
+	words := make([]string, len(tokens))
+
+	for i, t := range tokens {
+		words[i] = t.raw
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Parse derives a best-effort `Address` instance from a free-text address string like "165 10th Street,
+// Staten Island, NY 10306" or "16 E Main St Ste 400 Rochester NY 14614". Any components which can not
+// be determined are left empty. Component values retain the spelling and case of the original string.
+func Parse(raw string) *Address {
+
+	addr := &Address{}
+	tokens := tokenize(raw)
+
+	if len(tokens) == 0 {
+		return addr
+	}
+
+	// Work backwards from the end: country, postcode and region
+
+	if len(tokens) > 2 && isCountry(tokens[len(tokens)-1].key) {
+
+		prev := tokens[len(tokens)-2].key
+
+		if isPostcode(prev) || re_region.MatchString(prev) || re_postcode_ca_ldu.MatchString(prev) {
+			addr.Country = tokens[len(tokens)-1].raw
+			tokens = tokens[0 : len(tokens)-1]
+		}
+	}
+
+	if len(tokens) > 1 {
+
+		last := tokens[len(tokens)-1]
+
+		if isPostcode(last.key) {
+
+			addr.Postcode = last.raw
+			tokens = tokens[0 : len(tokens)-1]
+
+		} else if len(tokens) > 2 && re_postcode_ca_ldu.MatchString(last.key) && re_postcode_ca_fsa.MatchString(tokens[len(tokens)-2].key) {
+
+			addr.Postcode = joinTokens(tokens[len(tokens)-2:])
+			tokens = tokens[0 : len(tokens)-2]
+		}
+	}
+
+	if len(tokens) > 2 {
+
+		last := tokens[len(tokens)-1]
+
+		if re_region.MatchString(last.key) && !isDirectional(last.key) && !isStreetType(last.key) {
+			addr.Region = last.raw
+			tokens = tokens[0 : len(tokens)-1]
+		}
+	}
+
+	tokens = parseHouseNumberAndUnit(addr, tokens)
+
+	if len(tokens) == 0 {
+		return addr
+	}
+
+	// Street and locality
+
+	street_end := streetEnd(tokens)
+
+	addr.Street = strings.TrimRight(joinTokens(tokens[0:street_end]), ",")
+
+	if street_end < len(tokens) {
+		addr.Locality = strings.TrimRight(joinTokens(tokens[street_end:]), ",")
+	}
+
+	return addr
+}
+
+// ParseStreet derives a best-effort `Address` instance from a street address string like "165 10th Street"
+// or "1 Hollow Ln Ste 202" populating the house number, street and unit components. Unlike `Parse` the
+// input string is assumed to contain no locality, region or postcode information.
+func ParseStreet(raw string) *Address {
+
+	addr := &Address{}
+
+	tokens := tokenize(raw)
+	tokens = parseHouseNumberAndUnit(addr, tokens)
+
+	addr.Street = strings.TrimRight(joinTokens(tokens), ",")
+	return addr
+}
+
+// parseHouseNumberAndUnit assigns the house number and unit components in 'tokens' to 'addr' and returns
+// the remaining tokens.
+func parseHouseNumberAndUnit(addr *Address, tokens []*token) []*token {
+
+	if len(tokens) > 1 && re_housenumber.MatchString(tokens[0].key) {
+		addr.HouseNumber = tokens[0].raw
+		tokens = tokens[1:]
+	}
+
+	remaining := make([]*token, 0)
+
+	for i := 0; i < len(tokens); i++ {
+
+		t := tokens[i]
+
+		if isUnitDesignator(t.key) && i+1 < len(tokens) && isUnitValue(tokens[i+1].key) {
+
+			addr.Unit = strings.TrimSpace(strings.Join([]string{addr.Unit, t.raw, tokens[i+1].raw}, " "))
+
+			if tokens[i+1].comma && len(remaining) > 0 {
+				remaining[len(remaining)-1].comma = true
+			}
+
+			i += 1
+			continue
+		}
+
+		if strings.HasPrefix(t.key, "#") && len(t.key) > 1 {
+
+			addr.Unit = strings.TrimSpace(strings.Join([]string{addr.Unit, t.raw}, " "))
+
+			if t.comma && len(remaining) > 0 {
+				remaining[len(remaining)-1].comma = true
+			}
+
+			continue
+		}
+
+		remaining = append(remaining, t)
+	}
+
+	return remaining
+}
+
+// streetEnd returns the index of the first token after the street name in 'tokens'.
+func streetEnd(tokens []*token) int {
+
+	// If there are commas then the first comma-delimited segment is assumed to be the street
+
+	segment_end := len(tokens)
+
+	for i, t := range tokens {
+
+		if t.comma {
+			segment_end = i + 1
+			break
+		}
+	}
+
+	if segment_end < len(tokens) {
+		return segment_end
+	}
+
+	// Otherwise find the last street type (St, Avenue, etc.) in the string
+
+	last := -1
+
+	for i, t := range tokens {
+
+		if !isStreetType(t.key) {
+			continue
+		}
+
+		// "St. Marks Pl" (saint) rather than "10 St" (street)
+
+		if isSaint(tokens, i) {
+			continue
+		}
+
+		// Don't let a street type consume the entire string unless it is the last word (for
+		// example "Park Ave" versus "Avenue Road")
+
+		if i == 0 && len(tokens) > 1 && !isPrefixStreetType(t.key) {
+			continue
+		}
+
+		last = i
+	}
+
+	if last == -1 {
+		return len(tokens)
+	}
+
+	// Street types which precede the name of the street (rue, chemin, etc.) don't tell us where the
+	// street ends so assume it runs to the end of the string
+
+	if isPrefixStreetType(tokens[last].key) {
+		return len(tokens)
+	}
+
+	end := last + 1
+
+	// Trailing abbreviated directionals like "16 Main St W" but not "Merrick Rd North Merrick"
+
+	if end < len(tokens) && isDirectional(tokens[end].key) && len(tokens[end].key) <= 2 {
+		end += 1
+	}
+
+	return end
+}
+
+// isSaint returns true if the token at 'idx' in 'tokens' should be read as "Saint" rather than "Street".
+func isSaint(tokens []*token, idx int) bool {
+
+	t := tokens[idx]
+
+	if t.key != "st" && t.key != "ste" {
+		return false
+	}
+
+	if idx+1 >= len(tokens) {
+		return false
+	}
+
+	next := tokens[idx+1]
+
+	if isDirectional(next.key) && len(next.key) <= 2 {
+		return false
+	}
+
+	if t.key == "ste" {
+		// "Ste" is also an abbreviation for "suite" which is handled elsewhere
+		return !isUnitValue(next.key)
+	}
+
+	return t.period || idx == 0 || isStreetType(tokens[idx-1].key)
+}
+
+func isPostcode(k string) bool {
+	return re_postcode_us.MatchString(k) || re_postcode_ca.MatchString(k)
+}
+
+func isUnitValue(k string) bool {
+
+	if k == "" {
+		return false
+	}
+
+	if re_ordinal.MatchString(k) {
+		return true
+	}
+
+	for _, r := range k {
+
+		if r >= '0' && r <= '9' {
+			return true
+		}
+	}
+
+	return len(k) == 1
+}
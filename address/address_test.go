@@ -0,0 +1,122 @@
+package address
+
+import (
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+
+	tests := map[string]*Address{
+		"16 E Main St Ste 400 Rochester NY 14614": &Address{
+			HouseNumber: "16",
+			Street:      "E Main St",
+			Unit:        "Ste 400",
+			Locality:    "Rochester",
+			Region:      "NY",
+			Postcode:    "14614",
+		},
+		"165 10th Street, Staten Island, NY 10306": &Address{
+			HouseNumber: "165",
+			Street:      "10th Street",
+			Locality:    "Staten Island",
+			Region:      "NY",
+			Postcode:    "10306",
+		},
+		"16 Main St W Rochester NY 14614 US": &Address{
+			HouseNumber: "16",
+			Street:      "Main St W",
+			Locality:    "Rochester",
+			Region:      "NY",
+			Postcode:    "14614",
+			Country:     "US",
+		},
+		"69 Merrick Rd North Merrick NY 11566": &Address{
+			HouseNumber: "69",
+			Street:      "Merrick Rd",
+			Locality:    "North Merrick",
+			Region:      "NY",
+			Postcode:    "11566",
+		},
+		"124 rue St. Viateur o. #2": &Address{
+			HouseNumber: "124",
+			Street:      "rue St. Viateur o.",
+			Unit:        "#2",
+		},
+		"Avenue Road": &Address{
+			Street: "Avenue Road",
+		},
+	}
+
+	for raw, expected := range tests {
+
+		addr := Parse(raw)
+
+		if *addr != *expected {
+			t.Fatalf("Unexpected result parsing '%s': %v", raw, addr)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+
+	same := [][]string{
+		[]string{
+			"165 10 St Staten Island NY 10306",
+			"165 10th Street Staten Island NY 10306",
+		},
+		[]string{
+			"82-07 153rd Ave. Howard Beach NY 11414",
+			"8207 153rd Avenue Howard Beach NY 11414",
+		},
+		[]string{
+			"1 Hollow Ln Ste 202 New Hyde Park NY 11042",
+			"1 Hollow Lane, New Hyde Park, NY 11042",
+		},
+		[]string{
+			"20 W Lincoln Ave",
+			"20 West Lincoln Avenue",
+		},
+		[]string{
+			"100 Fifth Ave",
+			"100 5th Avenue",
+		},
+	}
+
+	for _, pair := range same {
+
+		a := Normalize(pair[0])
+		b := Normalize(pair[1])
+
+		if a != b {
+			t.Fatalf("Expected '%s' and '%s' to normalize to the same value, got '%s' and '%s'", pair[0], pair[1], a, b)
+		}
+	}
+
+	different := [][]string{
+		[]string{
+			"20 W Lincoln Ave Valley Stream NY 11580",
+			"20 E Lincoln Ave Valley Stream NY 11580",
+		},
+		[]string{
+			"775 Park Dr Huntington Station NY 11793",
+			"775 Park Ave Huntington NY 11743",
+		},
+	}
+
+	for _, pair := range different {
+
+		a := Normalize(pair[0])
+		b := Normalize(pair[1])
+
+		if a == b {
+			t.Fatalf("Expected '%s' and '%s' to normalize to different values, got '%s'", pair[0], pair[1], a)
+		}
+	}
+
+	expected := "165 10th street staten island ny 10306"
+	v := Normalize("165 10 St, Staten Island, NY 10306")
+
+	if v != expected {
+		t.Fatalf("Unexpected normalized value '%s', expected '%s'", v, expected)
+	}
+}
@@ -0,0 +1,159 @@
+package address
+
+// The lookup tables in this file are not meant to be exhaustive. They cover the abbreviations
+// most commonly encountered in the data sources supported by this package.
+
+// street_types maps (lower-cased) street type abbreviations to their expanded form.
+var street_types = map[string]string{
+	"alley":      "alley",
+	"aly":        "alley",
+	"av":         "avenue",
+	"ave":        "avenue",
+	"avenue":     "avenue",
+	"blvd":       "boulevard",
+	"boul":       "boulevard",
+	"boulevard":  "boulevard",
+	"cir":        "circle",
+	"circle":     "circle",
+	"cres":       "crescent",
+	"crescent":   "crescent",
+	"ct":         "court",
+	"court":      "court",
+	"dr":         "drive",
+	"drive":      "drive",
+	"expressway": "expressway",
+	"expy":       "expressway",
+	"freeway":    "freeway",
+	"fwy":        "freeway",
+	"highway":    "highway",
+	"hwy":        "highway",
+	"lane":       "lane",
+	"ln":         "lane",
+	"parkway":    "parkway",
+	"pkwy":       "parkway",
+	"pl":         "place",
+	"place":      "place",
+	"plaza":      "plaza",
+	"plz":        "plaza",
+	"rd":         "road",
+	"road":       "road",
+	"sq":         "square",
+	"square":     "square",
+	"st":         "street",
+	"str":        "street",
+	"street":     "street",
+	"ter":        "terrace",
+	"terrace":    "terrace",
+	"tpke":       "turnpike",
+	"trail":      "trail",
+	"trl":        "trail",
+	"turnpike":   "turnpike",
+	"way":        "way",
+	// Street types which precede the street name
+	"chemin": "chemin",
+	"ch":     "chemin",
+	"rue":    "rue",
+}
+
+// prefix_street_types is the list of street types which precede, rather than follow, the name of a street.
+var prefix_street_types = map[string]bool{
+	"boul":   true,
+	"chemin": true,
+	"ch":     true,
+	"rue":    true,
+}
+
+// directionals maps (lower-cased) directional abbreviations to their expanded form.
+var directionals = map[string]string{
+	"e":         "east",
+	"east":      "east",
+	"n":         "north",
+	"ne":        "northeast",
+	"north":     "north",
+	"northeast": "northeast",
+	"northwest": "northwest",
+	"nw":        "northwest",
+	"s":         "south",
+	"se":        "southeast",
+	"south":     "south",
+	"southeast": "southeast",
+	"southwest": "southwest",
+	"sw":        "southwest",
+	"w":         "west",
+	"west":      "west",
+}
+
+// unit_designators maps (lower-cased) unit designators to their expanded form.
+var unit_designators = map[string]string{
+	"#":         "#",
+	"apartment": "apartment",
+	"apt":       "apartment",
+	"bldg":      "building",
+	"building":  "building",
+	"dept":      "department",
+	"fl":        "floor",
+	"floor":     "floor",
+	"rm":        "room",
+	"room":      "room",
+	"ste":       "suite",
+	"suite":     "suite",
+	"unit":      "unit",
+}
+
+// ordinal_words maps (lower-cased) ordinal words to their numeric form.
+var ordinal_words = map[string]string{
+	"first":       "1st",
+	"second":      "2nd",
+	"third":       "3rd",
+	"fourth":      "4th",
+	"fifth":       "5th",
+	"sixth":       "6th",
+	"seventh":     "7th",
+	"eighth":      "8th",
+	"ninth":       "9th",
+	"tenth":       "10th",
+	"eleventh":    "11th",
+	"twelfth":     "12th",
+	"thirteenth":  "13th",
+	"fourteenth":  "14th",
+	"fifteenth":   "15th",
+	"sixteenth":   "16th",
+	"seventeenth": "17th",
+	"eighteenth":  "18th",
+	"nineteenth":  "19th",
+	"twentieth":   "20th",
+}
+
+// countries is the list of (lower-cased) country names and codes which may appear at the end of an address.
+var countries = map[string]bool{
+	"ca":     true,
+	"can":    true,
+	"canada": true,
+	"us":     true,
+	"usa":    true,
+}
+
+func isStreetType(k string) bool {
+	_, exists := street_types[k]
+	return exists
+}
+
+func isPrefixStreetType(k string) bool {
+	_, exists := prefix_street_types[k]
+	return exists
+}
+
+func isDirectional(k string) bool {
+	_, exists := directionals[k]
+	return exists
+}
+
+func isUnitDesignator(k string) bool {
+	_, exists := unit_designators[k]
+	return exists
+}
+
+func isCountry(k string) bool {
+	_, exists := countries[k]
+	return exists
+}
@@ -0,0 +1,162 @@
+package address
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Normalize parses 'raw' and returns a lower-cased string with abbreviations (St, Ave, W) expanded,
+// numbered streets converted to ordinals ("10 St" becomes "10th street") and any unit or suite
+// designators removed. The intent is that two different spellings of the same address will yield the
+// same normalized string.
+func Normalize(raw string) string {
+	return Parse(raw).Normalize().String()
+}
+
+// NormalizeStreet returns a lower-cased version of 'street' with street types and directionals expanded
+// and numbered streets converted to ordinals. Unlike `Normalize` the input is assumed to be a street
+// name only (for example "W 10 St" or "Lincoln Ave").
+func NormalizeStreet(street string) string {
+
+	tokens := tokenize(street)
+	words := make([]string, 0)
+
+	for i, t := range tokens {
+
+		k := strings.Trim(t.key, ".")
+
+		if k == "" || k == "#" {
+			continue
+		}
+
+		if v, exists := ordinal_words[k]; exists {
+			words = append(words, v)
+			continue
+		}
+
+		if isStreetType(k) {
+
+			if isSaint(tokens, i) {
+
+				if k == "ste" {
+					words = append(words, "sainte")
+				} else {
+					words = append(words, "saint")
+				}
+
+				continue
+			}
+
+			words = append(words, street_types[k])
+			continue
+		}
+
+		if isDirectional(k) {
+			words = append(words, directionals[k])
+			continue
+		}
+
+		if re_number.MatchString(k) && i+1 < len(tokens) && isStreetType(tokens[i+1].key) && !isSaint(tokens, i+1) {
+			words = append(words, ordinal(k))
+			continue
+		}
+
+		words = append(words, k)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Normalize returns a new `Address` instance with normalized (lower-cased, expanded) components. The
+// unit component is always removed.
+func (a *Address) Normalize() *Address {
+
+	n := &Address{
+		HouseNumber: strings.ReplaceAll(strings.ToLower(a.HouseNumber), "-", ""),
+		Street:      NormalizeStreet(a.Street),
+		Locality:    normalizeWords(a.Locality),
+		Region:      normalizeWords(a.Region),
+		Postcode:    strings.ReplaceAll(strings.ToLower(a.Postcode), " ", ""),
+		Country:     normalizeCountry(a.Country),
+	}
+
+	return n
+}
+
+// String returns the non-empty address components as a space-separated string.
+func (a *Address) String() string {
+
+	parts := make([]string, 0)
+
+	for _, v := range []string{
+		a.HouseNumber,
+		a.Street,
+		a.Unit,
+		a.Locality,
+		a.Region,
+		a.Postcode,
+		a.Country,
+	} {
+
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// ordinal returns the ordinal form of the number 'k' (for example "10" becomes "10th").
+func ordinal(k string) string {
+
+	i, err := strconv.Atoi(k)
+
+	if err != nil {
+		return k
+	}
+
+	suffix := "th"
+
+	switch i % 100 {
+	case 11, 12, 13:
+		// pass
+	default:
+
+		switch i % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+
+	return fmt.Sprintf("%d%s", i, suffix)
+}
+
+func normalizeWords(s string) string {
+
+	words := make([]string, 0)
+
+	for _, t := range tokenize(s) {
+		words = append(words, t.key)
+	}
+
+	return strings.Join(words, " ")
+}
+
+func normalizeCountry(s string) string {
+
+	k := normalizeWords(s)
+
+	switch k {
+	case "usa":
+		return "us"
+	case "can", "canada":
+		return "ca"
+	default:
+		return k
+	}
+}
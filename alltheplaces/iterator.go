@@ -1,23 +1,33 @@
 package alltheplaces
 
 // > go run cmd/index-locations/main.go -verbose -location-database-uri null:// -location-parser-uri alltheplaces:// -iterator-uri alltheplaces:// /usr/local/data/alltheplaces/*.geojson
+// > go run cmd/index-locations/main.go -verbose -location-database-uri null:// -location-parser-uri alltheplaces:// -iterator-uri alltheplaces:// /usr/local/data/alltheplaces/output.zip
 
 import (
+	"archive/zip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 
-	"github.com/paulmach/orb/geojson"
+	"github.com/aaronland/gocloud-blob/bucket"
 	"github.com/whosonfirst/go-dedupe/iterator"
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/fileblob"
 )
 
 type AllThePlacesIterator struct {
 	iterator.Iterator
+	// An optional bucket to read files from. If nil files are read from the local filesystem.
+	bucket      *blob.Bucket
 	max_workers int
 }
 
@@ -29,6 +39,13 @@ func init() {
 	}
 }
 
+// NewAllThePlacesIterator returns a new `AllThePlacesIterator` instance configured by 'uri' which is expected to take the form of:
+//
+//	alltheplaces://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?bucket-uri=` A valid `gocloud.dev/blob` URI to read files from. If empty files are read from the local filesystem.
+// * `?max-workers=` The maximum number of callbacks to invoke concurrently. Default is 20.
 func NewAllThePlacesIterator(ctx context.Context, uri string) (iterator.Iterator, error) {
 
 	u, err := url.Parse(uri)
@@ -56,9 +73,25 @@ func NewAllThePlacesIterator(ctx context.Context, uri string) (iterator.Iterator
 		max_workers: max_workers,
 	}
 
+	bucket_uri := q.Get("bucket-uri")
+
+	if bucket_uri != "" {
+
+		source_bucket, err := bucket.OpenBucket(ctx, bucket_uri)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open bucket, %w", err)
+		}
+
+		iter.bucket = source_bucket
+	}
+
 	return iter, nil
 }
 
+// IterateWithCallback processes one or more All The Places GeoJSON FeatureCollection files, or zip archives (like
+// the `output.zip` file published with each All The Places release) containing FeatureCollection files. Features
+// are decoded one at a time and passed to 'cb' without reading the whole FeatureCollection in to memory.
 func (iter *AllThePlacesIterator) IterateWithCallback(ctx context.Context, cb iterator.IteratorCallback, uris ...string) error {
 
 	throttle := make(chan bool, iter.max_workers)
@@ -69,6 +102,29 @@ func (iter *AllThePlacesIterator) IterateWithCallback(ctx context.Context, cb it
 
 	wg := new(sync.WaitGroup)
 
+	defer wg.Wait()
+
+	feature_cb := func(ctx context.Context, path string, offset int, body []byte) {
+
+		<-throttle
+
+		wg.Add(1)
+
+		go func(offset int, body []byte) {
+
+			defer func() {
+				wg.Done()
+				throttle <- true
+			}()
+
+			err := cb(ctx, body)
+
+			if err != nil {
+				slog.Error("Callback failed for record", "path", path, "offset", offset, "error", err)
+			}
+		}(offset, body)
+	}
+
 	for _, path := range uris {
 
 		logger := slog.Default()
@@ -76,61 +132,271 @@ func (iter *AllThePlacesIterator) IterateWithCallback(ctx context.Context, cb it
 
 		logger.Debug("Process record")
 
-		r, err := os.Open(path)
+		var err error
+
+		if strings.HasSuffix(path, ".zip") {
+			err = iter.iterateZip(ctx, path, feature_cb)
+		} else {
+			err = iter.iterateFile(ctx, path, feature_cb)
+		}
 
 		if err != nil {
-			return fmt.Errorf("Failed to open %s for reading, %v", path, err)
+			return err
 		}
+	}
+
+	return nil
+}
+
+func (iter *AllThePlacesIterator) Close(ctx context.Context) error {
+
+	if iter.bucket != nil {
+		return iter.bucket.Close()
+	}
 
-		defer r.Close()
+	return nil
+}
+
+// featureCallback is the function invoked for each (encoded) feature decoded from the FeatureCollection file 'path'.
+type featureCallback func(ctx context.Context, path string, offset int, body []byte)
+
+// iterateFile processes the FeatureCollection file 'path'.
+func (iter *AllThePlacesIterator) iterateFile(ctx context.Context, path string, cb featureCallback) error {
+
+	var r io.ReadCloser
 
-		body, err := io.ReadAll(r)
+	if iter.bucket != nil {
+
+		bucket_r, err := iter.bucket.NewReader(ctx, path, nil)
 
 		if err != nil {
-			return fmt.Errorf("Failed to read %s, %v", path, err)
+			return fmt.Errorf("Failed to open %s for reading, %w", path, err)
 		}
 
-		fc, err := geojson.UnmarshalFeatureCollection(body)
+		r = bucket_r
+
+	} else {
+
+		fh, err := os.Open(path)
 
 		if err != nil {
-			logger.Warn("Failed to unmarshal feature collection", "path", path, "error", err)
-			continue
+			return fmt.Errorf("Failed to open %s for reading, %w", path, err)
 		}
 
-		for offset, f := range fc.Features {
+		r = fh
+	}
+
+	defer r.Close()
+
+	err := decodeFeatureCollection(ctx, r, path, cb)
+
+	if err != nil {
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		slog.Warn("Failed to decode feature collection", "path", path, "error", err)
+	}
+
+	return nil
+}
+
+// iterateZip processes each FeatureCollection (".geojson") file in the zip archive 'path'.
+func (iter *AllThePlacesIterator) iterateZip(ctx context.Context, path string, cb featureCallback) error {
+
+	local_path := path
+
+	// Reading a zip archive requires random access to it so archives in a bucket are copied to a temporary
+	// file first rather than being read with (many, small) range requests.
+
+	if iter.bucket != nil {
+
+		tmp_path, err := iter.downloadFile(ctx, path)
+
+		if err != nil {
+			return err
+		}
+
+		defer os.Remove(tmp_path)
+		local_path = tmp_path
+	}
+
+	fh, err := os.Open(local_path)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open %s for reading, %w", path, err)
+	}
+
+	defer fh.Close()
+
+	info, err := fh.Stat()
+
+	if err != nil {
+		return fmt.Errorf("Failed to stat %s, %w", path, err)
+	}
+
+	zr, err := zip.NewReader(fh, info.Size())
+
+	if err != nil {
+		return fmt.Errorf("Failed to open %s as a zip archive, %w", path, err)
+	}
+
+	for _, zf := range zr.File {
 
-			<-throttle
+		if zf.FileInfo().IsDir() || filepath.Ext(zf.Name) != ".geojson" {
+			continue
+		}
 
-			wg.Add(1)
+		zf_path := fmt.Sprintf("%s#%s", path, zf.Name)
 
-			go func(offset int, f *geojson.Feature) {
+		r, err := zf.Open()
 
-				defer func() {
-					wg.Done()
-					throttle <- true
-				}()
+		if err != nil {
+			return fmt.Errorf("Failed to open %s for reading, %w", zf_path, err)
+		}
 
-				body, err := f.MarshalJSON()
+		err = decodeFeatureCollection(ctx, r, zf_path, cb)
+		r.Close()
 
-				if err != nil {
-					logger.Error("Failed to marshal record", "offset", offset, "error", err)
-					return
-				}
+		if err != nil {
 
-				err = cb(ctx, body)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 
-				if err != nil {
-					logger.Error("Callback failed for record", "offset", offset, "error", err)
-					return
-				}
-			}(offset, f)
+			slog.Warn("Failed to decode feature collection", "path", zf_path, "error", err)
 		}
 	}
 
-	wg.Wait()
 	return nil
 }
 
-func (iter *AllThePlacesIterator) Close(ctx context.Context) error {
+// downloadFile copies 'path' from the iterator's bucket to a new temporary file and returns the path of that file.
+// It is the caller's responsibility to remove the file when it is no longer needed.
+func (iter *AllThePlacesIterator) downloadFile(ctx context.Context, path string) (string, error) {
+
+	r, err := iter.bucket.NewReader(ctx, path, nil)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to open %s for reading, %w", path, err)
+	}
+
+	defer r.Close()
+
+	wr, err := os.CreateTemp("", "alltheplaces-*.zip")
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to create temporary file for %s, %w", path, err)
+	}
+
+	tmp_path := wr.Name()
+
+	_, err = io.Copy(wr, r)
+
+	if err != nil {
+		wr.Close()
+		os.Remove(tmp_path)
+		return "", fmt.Errorf("Failed to copy %s to %s, %w", path, tmp_path, err)
+	}
+
+	err = wr.Close()
+
+	if err != nil {
+		os.Remove(tmp_path)
+		return "", fmt.Errorf("Failed to close %s, %w", tmp_path, err)
+	}
+
+	return tmp_path, nil
+}
+
+// decodeFeatureCollection reads the GeoJSON FeatureCollection in 'r' and invokes 'cb' for each of the elements in
+// its "features" array, as they are decoded. All other top-level properties are ignored.
+func decodeFeatureCollection(ctx context.Context, r io.Reader, path string, cb featureCallback) error {
+
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+
+	if err != nil {
+
+		// Some spiders yield empty files
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return err
+	}
+
+	if tok != json.Delim('{') {
+		return fmt.Errorf("Expected FeatureCollection object")
+	}
+
+	for dec.More() {
+
+		tok, err := dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		if tok != "features" {
+
+			var v json.RawMessage
+
+			err := dec.Decode(&v)
+
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		tok, err = dec.Token()
+
+		if err != nil {
+			return err
+		}
+
+		if tok == nil {
+			continue
+		}
+
+		if tok != json.Delim('[') {
+			return fmt.Errorf("Expected 'features' array")
+		}
+
+		offset := 0
+
+		for dec.More() {
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+				// pass
+			}
+
+			var body json.RawMessage
+
+			err := dec.Decode(&body)
+
+			if err != nil {
+				return fmt.Errorf("Failed to decode feature at offset %d, %w", offset, err)
+			}
+
+			cb(ctx, path, offset, body)
+			offset += 1
+		}
+
+		// Closing ']'
+		_, err = dec.Token()
+
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
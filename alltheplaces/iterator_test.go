@@ -0,0 +1,130 @@
+package alltheplaces
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/whosonfirst/go-dedupe/iterator"
+)
+
+func TestAllThePlacesIterator(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "alltheplaces")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	// Two spiders, one of which has (other) top-level properties after its features, and an empty file
+
+	spiders := map[string]string{
+		"output/cafes.geojson": testFeatureCollection(3, `"dataset_attributes":{"@spider":"cafes"},`, ""),
+		"output/shops.geojson": testFeatureCollection(2, "", `,"dataset_attributes":{"@spider":"shops"}`),
+		"output/empty.geojson": "",
+		"output/README.md":     `{"features":[{}]}`,
+	}
+
+	for name, body := range spiders {
+
+		path := filepath.Join(tmp_dir, name)
+
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+
+		if err != nil {
+			t.Fatalf("Failed to create %s, %v", filepath.Dir(path), err)
+		}
+
+		err = os.WriteFile(path, []byte(body), 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", path, err)
+		}
+	}
+
+	zip_path := filepath.Join(tmp_dir, "output.zip")
+
+	zip_fh, err := os.Create(zip_path)
+
+	if err != nil {
+		t.Fatalf("Failed to create %s, %v", zip_path, err)
+	}
+
+	zw := zip.NewWriter(zip_fh)
+
+	for name, body := range spiders {
+
+		wr, err := zw.Create(name)
+
+		if err != nil {
+			t.Fatalf("Failed to create %s in zip archive, %v", name, err)
+		}
+
+		wr.Write([]byte(body))
+	}
+
+	zw.Close()
+	zip_fh.Close()
+
+	tests := []struct {
+		iterator_uri string
+		paths        []string
+	}{
+		{"alltheplaces://", []string{filepath.Join(tmp_dir, "output/cafes.geojson"), filepath.Join(tmp_dir, "output/shops.geojson"), filepath.Join(tmp_dir, "output/empty.geojson")}},
+		{"alltheplaces://", []string{zip_path}},
+		{fmt.Sprintf("alltheplaces://?bucket-uri=file://%s", tmp_dir), []string{"output/cafes.geojson", "output/shops.geojson"}},
+		{fmt.Sprintf("alltheplaces://?bucket-uri=file://%s&max-workers=1", tmp_dir), []string{"output.zip"}},
+	}
+
+	for _, test := range tests {
+
+		iter, err := iterator.NewIterator(ctx, test.iterator_uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create iterator for %s, %v", test.iterator_uri, err)
+		}
+
+		count := int32(0)
+
+		iter_cb := func(ctx context.Context, body []byte) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		}
+
+		err = iter.IterateWithCallback(ctx, iter_cb, test.paths...)
+
+		if err != nil {
+			t.Fatalf("Failed to iterate %v with %s, %v", test.paths, test.iterator_uri, err)
+		}
+
+		iter.Close(ctx)
+
+		if count != 5 {
+			t.Fatalf("Expected 5 features iterating %v with %s, got %d", test.paths, test.iterator_uri, count)
+		}
+	}
+}
+
+func testFeatureCollection(count int, before string, after string) string {
+
+	features := ""
+
+	for i := 0; i < count; i++ {
+
+		if i > 0 {
+			features += ","
+		}
+
+		features += fmt.Sprintf(`{"type":"Feature","id":"%d","geometry":{"type":"Point","coordinates":[-73.6,45.5]},"properties":{"name":"Place %d"}}`, i, i)
+	}
+
+	return fmt.Sprintf(`{"type":"FeatureCollection",%s"features":[%s]%s}`, before, features, after)
+}
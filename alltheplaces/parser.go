@@ -5,17 +5,21 @@ import (
 	"fmt"
 	_ "log"
 	"log/slog"
+	"net/url"
 	"strings"
 
 	"github.com/paulmach/orb/geojson"
 	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/category"
 	"github.com/whosonfirst/go-dedupe/location"
 )
 
 type AllThePlacesVenueParser struct {
 	location.Parser
-	addr_keys []string
+	addr_keys     []string
+	category_keys []string
+	release       string
 }
 
 func init() {
@@ -29,6 +33,14 @@ func init() {
 
 func NewAllThePlacesVenueParser(ctx context.Context, uri string) (location.Parser, error) {
 
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
 	addr_keys := []string{
 		"addr:street_address",
 		"addr:city",
@@ -36,8 +48,21 @@ func NewAllThePlacesVenueParser(ctx context.Context, uri string) (location.Parse
 		"addr:country",
 	}
 
+	// The (OSM) tags to derive a category from, in order of precedence
+	category_keys := []string{
+		"amenity",
+		"healthcare",
+		"shop",
+		"tourism",
+		"leisure",
+		"office",
+		"@spider",
+	}
+
 	p := &AllThePlacesVenueParser{
-		addr_keys: addr_keys,
+		addr_keys:     addr_keys,
+		category_keys: category_keys,
+		release:       q.Get("release"),
 	}
 
 	return p, nil
@@ -77,10 +102,24 @@ func (p *AllThePlacesVenueParser) Parse(ctx context.Context, body []byte) (*loca
 		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing 'address' properties"))
 	}
 
-	// Something something something libpostal...
-
 	addr := strings.Join(addr_components, " ")
 
+	components := &location.AddressComponents{
+		HouseNumber: gjson.GetBytes(body, "properties.addr:housenumber").String(),
+		Street:      gjson.GetBytes(body, "properties.addr:street").String(),
+		Unit:        gjson.GetBytes(body, "properties.addr:unit").String(),
+		Locality:    gjson.GetBytes(body, "properties.addr:city").String(),
+		Region:      gjson.GetBytes(body, "properties.addr:state").String(),
+		Postcode:    gjson.GetBytes(body, "properties.addr:postcode").String(),
+		Country:     gjson.GetBytes(body, "properties.addr:country").String(),
+	}
+
+	if components.Street == "" {
+		components.Street = gjson.GetBytes(body, "properties.addr:street_address").String()
+	}
+
+	components.ParseStreet()
+
 	geom_rsp := gjson.GetBytes(body, "geometry")
 
 	if !geom_rsp.Exists() || geom_rsp.String() == "" {
@@ -100,11 +139,57 @@ func (p *AllThePlacesVenueParser) Parse(ctx context.Context, body []byte) (*loca
 	c_id := dedupe.AllThePlacesId(id)
 
 	c := &location.Location{
-		ID:       c_id,
-		Name:     name,
-		Address:  addr,
-		Centroid: &centroid,
+		ID:                c_id,
+		Name:              name,
+		Address:           addr,
+		Centroid:          &centroid,
+		AddressComponents: components,
+		Category:          p.category(body),
+		Provenance: &location.Provenance{
+			Source:  "alltheplaces",
+			Dataset: gjson.GetBytes(body, "properties.@spider").String(),
+			Release: p.release,
+		},
+	}
+
+	// OSM tags may contain multiple values separated by semi-colons
+
+	for _, phone := range strings.Split(gjson.GetBytes(body, "properties.phone").String(), ";") {
+		c.AddPhone(phone, components.Country)
+	}
+
+	for _, website := range strings.Split(gjson.GetBytes(body, "properties.website").String(), ";") {
+		c.AddWebsite(website)
 	}
 
 	return c, nil
 }
+
+// category returns the normalized category derived from the first of the parser's category keys that
+// yields a known category.
+func (p *AllThePlacesVenueParser) category(body []byte) string {
+
+	for _, k := range p.category_keys {
+
+		path := fmt.Sprintf("properties.%s", k)
+		rsp := gjson.GetBytes(body, path)
+
+		if !rsp.Exists() || rsp.String() == "" {
+			continue
+		}
+
+		c := category.Normalize(rsp.String())
+
+		// Any shop=* tag is a shop, even if we don't recognize its value
+
+		if c == category.UNKNOWN && k == "shop" {
+			c = category.SHOPPING
+		}
+
+		if c != category.UNKNOWN {
+			return c
+		}
+	}
+
+	return category.UNKNOWN
+}
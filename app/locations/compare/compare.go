@@ -113,6 +113,9 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 		VectorDatabaseURI:         vector_database_uri,
 		Workers:                   workers,
 		Threshold:                 threshold,
+		CheckCategories:           check_categories,
+		IncludeNeighbors:          include_neighbors,
+		BlockOnRegion:             block_on_region,
 	}
 
 	err := wof_compare.CompareLocationDatabases(ctx, cmp_opts)
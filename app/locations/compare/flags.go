@@ -21,6 +21,9 @@ var monitor_uri string
 var workers int
 
 var threshold float64
+var check_categories bool
+var include_neighbors bool
+var block_on_region bool
 var verbose bool
 
 func DefaultFlagSet() *flag.FlagSet {
@@ -36,12 +39,18 @@ func DefaultFlagSet() *flag.FlagSet {
 	fs.StringVar(&vector_database_model, "vector-database-model", "mxbai-embed-large", "The name of the model to use comparing records in the location database against records in the vector database. This value will be used to replace any \"{vector-database-model}\" strings in the -vector-database-uri and -vector-database-embedder-uri flags.")
 
 	fs.StringVar(&source_location_database_uri, "source-location-database-uri", "", "A valid whosonfirst/go-dedupe/location.Database URI.")
-	fs.StringVar(&target_location_database_uri, "target-location-database-uri", "", "A valid whosonfirst/go-dedupe/location.Database URI.")
+	fs.StringVar(&target_location_database_uri, "target-location-database-uri", "", "A valid whosonfirst/go-dedupe/location.Database URI. Records are compared in blocks defined by the geohashes returned by this database. Only the SQLDatabase implementation supports adaptive blocking (its ?max-block-size= parameter), where dense geohashes are split into longer child geohashes; all other implementations use 5-character geohashes. Geohashes are only ever split and never merged, so the sparse children of a split geohash are compared as small blocks of their own. Use the -include-neighbors flag to match records on either side of their boundaries.")
 
 	fs.StringVar(&monitor_uri, "monitor-uri", "counter://PT60S", "A valid sfomuseum/go-timings.Monitor URI.")
 
 	fs.Float64Var(&threshold, "threshold", 4.0, "The threshold value for matching records. Whether this value is greater than or lesser than a matching value will be dependent on the vector database in use.")
 
+	fs.BoolVar(&check_categories, "check-categories", false, "If true then reject matching records whose (normalized) categories are not compatible, for example a dentist and a pizzeria sharing the same address. Records with unknown categories are always considered compatible.")
+
+	fs.BoolVar(&include_neighbors, "include-neighbors", false, "If true then source records in the 8 geohashes neighbouring each target geohash are also compared against the target records in that geohash. This allows records on either side of a geohash boundary to be matched, at the cost of comparing more records.")
+
+	fs.BoolVar(&block_on_region, "block-on-region", false, "If true then records in each geohash are grouped by their Who's On First region and only compared with records in the same region. Records whose region is not known are compared with all the records in a geohash.")
+
 	fs.IntVar(&workers, "workers", 10, "The number of simultaneous worker processes to use.")
 	fs.BoolVar(&verbose, "verbose", false, "Enable verbose (debug) logging.")
 
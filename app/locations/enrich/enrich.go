@@ -0,0 +1,193 @@
+package enrich
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/sfomuseum/go-flags/flagset"
+	"github.com/sfomuseum/go-timings"
+	"github.com/whosonfirst/go-dedupe/iterator"
+	"github.com/whosonfirst/go-dedupe/location"
+	"github.com/whosonfirst/go-dedupe/pip"
+)
+
+func Run(ctx context.Context) error {
+	fs := DefaultFlagSet()
+	return RunWithFlagSet(ctx, fs)
+}
+
+func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
+
+	flagset.Parse(fs)
+
+	if verbose {
+		slog.SetLogLoggerLevel(slog.LevelDebug)
+		slog.Debug("Verbose logging enabled")
+	}
+
+	uris := fs.Args()
+
+	// Build the point-in-polygon index
+
+	idx := pip.NewIndex()
+
+	if polygons_database != "" {
+
+		conn, err := sql.Open("sqlite3", polygons_database)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open polygons database, %w", err)
+		}
+
+		defer conn.Close()
+
+		err = idx.AddFeaturesFromSQL(ctx, conn)
+
+		if err != nil {
+			return fmt.Errorf("Failed to index polygons database, %w", err)
+		}
+	}
+
+	if len(uris) > 0 {
+
+		iter, err := iterator.NewIterator(ctx, polygons_iterator_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create polygons iterator, %w", err)
+		}
+
+		defer iter.Close(ctx)
+
+		err = iter.IterateWithCallback(ctx, idx.AddFeature, uris...)
+
+		if err != nil {
+			return fmt.Errorf("Failed to index polygons, %w", err)
+		}
+	}
+
+	if idx.Count() == 0 {
+		return fmt.Errorf("No administrative polygons were indexed")
+	}
+
+	slog.Info("Indexed administrative polygons", "count", idx.Count())
+
+	db, err := location.NewDatabase(ctx, location_database_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new location database, %w", err)
+	}
+
+	defer db.Close(ctx)
+
+	// Read all the geohashes (and then the locations for each geohash) before updating anything since databases
+	// may be limited to a single connection
+
+	geohashes := make([]string, 0)
+
+	geohashes_cb := func(ctx context.Context, geohash string) error {
+		geohashes = append(geohashes, geohash)
+		return nil
+	}
+
+	err = db.GetGeohashes(ctx, geohashes_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to get geohashes, %w", err)
+	}
+
+	monitor, err := timings.NewMonitor(ctx, monitor_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create monitor, %w", err)
+	}
+
+	monitor.Start(ctx, os.Stderr)
+	defer monitor.Stop(ctx)
+
+	count_updated := 0
+
+	for _, geohash := range geohashes {
+
+		locations := make([]*location.Location, 0)
+
+		locations_cb := func(ctx context.Context, loc *location.Location) error {
+
+			if loc.Centroid == nil {
+				return nil
+			}
+
+			if loc.Hierarchy != nil && !overwrite {
+				return nil
+			}
+
+			locations = append(locations, loc)
+			return nil
+		}
+
+		err := db.GetWithGeohash(ctx, geohash, locations_cb)
+
+		if err != nil {
+			return fmt.Errorf("Failed to get locations with geohash %s, %w", geohash, err)
+		}
+
+		updates := make([]*location.Location, 0)
+
+		for _, loc := range locations {
+
+			h := idx.Hierarchy(*loc.Centroid)
+
+			if h == nil {
+				slog.Debug("No hierarchy for location", "id", loc.ID)
+				continue
+			}
+
+			loc.Hierarchy = h
+
+			// Locations retrieved from the database do not have a Feature property but databases which store
+			// source features keep them when a location is added without one so they don't need to be fetched here.
+
+			updates = append(updates, loc)
+		}
+
+		err = updateLocations(ctx, db, updates)
+
+		if err != nil {
+			return fmt.Errorf("Failed to update locations with geohash %s, %w", geohash, err)
+		}
+
+		count_updated += len(updates)
+		monitor.Signal(ctx)
+	}
+
+	slog.Info("Assigned hierarchies", "count", count_updated)
+	return nil
+}
+
+// updateLocations adds 'locs' to 'db', using a single batch if the database supports it.
+func updateLocations(ctx context.Context, db location.Database, locs []*location.Location) error {
+
+	if len(locs) == 0 {
+		return nil
+	}
+
+	batch_db, ok := db.(location.BatchDatabase)
+
+	if ok {
+		return batch_db.AddLocations(ctx, locs)
+	}
+
+	for _, loc := range locs {
+
+		err := db.AddLocation(ctx, loc)
+
+		if err != nil {
+			return fmt.Errorf("Failed to update location %s, %w", loc.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package enrich
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sfomuseum/go-flags/flagset"
+)
+
+var location_database_uri string
+
+var polygons_iterator_uri string
+var polygons_database string
+
+var overwrite bool
+
+var monitor_uri string
+
+var verbose bool
+
+func DefaultFlagSet() *flag.FlagSet {
+
+	fs := flagset.NewFlagSet("enrich")
+
+	fs.StringVar(&location_database_uri, "location-database-uri", "", "A valid whosonfirst/go-dedupe/location.Database URI.")
+
+	fs.StringVar(&polygons_iterator_uri, "polygons-iterator-uri", "whosonfirst://", "A valid whosonfirst/go-dedupe/iterator.Iterator URI used to read Who's On First administrative polygons from the URIs passed to the tool. For example \"whosonfirst://\" for a Who's On First repository or \"whosonfirst://?iterator-uri=featurecollection://\" for a GeoJSON FeatureCollection file.")
+	fs.StringVar(&polygons_database, "polygons-database", "", "The path to a Who's On First SQLite database to read administrative polygons from, in addition to any URIs passed to the tool.")
+
+	fs.BoolVar(&overwrite, "overwrite", false, "If true then update locations which already have a hierarchy. If false, they are left as-is.")
+
+	fs.StringVar(&monitor_uri, "monitor-uri", "counter://PT60S", "A valid sfomuseum/go-timings.Monitor URI.")
+	fs.BoolVar(&verbose, "verbose", false, "Enable verbose (debug) logging.")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Assign Who's On First hierarchies to the records in a location database using an offline point-in-polygon lookup.\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\t %s [options] uri(N) uri(N)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Valid options are:\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
@@ -0,0 +1,165 @@
+package exporter
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/aaronland/gocloud-blob/bucket"
+	"github.com/dsnet/compress/bzip2"
+	"github.com/sfomuseum/go-flags/flagset"
+	"github.com/sfomuseum/go-timings"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+const (
+	// FORMAT_JSONL indicates that locations are exported as one JSON-encoded `location.Location` per line.
+	FORMAT_JSONL string = "jsonl"
+	// FORMAT_GEOJSONL indicates that locations are exported as one GeoJSON Feature per line.
+	FORMAT_GEOJSONL string = "geojsonl"
+)
+
+func Run(ctx context.Context) error {
+	fs := DefaultFlagSet()
+	return RunWithFlagSet(ctx, fs)
+}
+
+func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
+
+	flagset.Parse(fs)
+
+	if verbose {
+		slog.SetLogLoggerLevel(slog.LevelDebug)
+		slog.Debug("Verbose logging enabled")
+	}
+
+	if format != FORMAT_JSONL && format != FORMAT_GEOJSONL {
+		return fmt.Errorf("Invalid -format flag")
+	}
+
+	args := fs.Args()
+
+	if len(args) != 1 {
+		return fmt.Errorf("Missing or too many paths to export locations to")
+	}
+
+	path := args[0]
+
+	db, err := location.NewDatabase(ctx, location_database_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new location database, %w", err)
+	}
+
+	defer db.Close(ctx)
+
+	var wr io.WriteCloser
+
+	if path == "-" {
+		wr = os.Stdout
+	} else {
+
+		b, err := bucket.OpenBucket(ctx, bucket_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open bucket, %w", err)
+		}
+
+		defer b.Close()
+
+		bucket_wr, err := b.NewWriter(ctx, path, nil)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create new writer for %s, %w", path, err)
+		}
+
+		wr = bucket_wr
+	}
+
+	// Closing the bucket writer is what actually (atomically) publishes the export so errors
+	// closing it are returned rather than deferred.
+
+	var out io.Writer = wr
+	var bz_wr *bzip2.Writer
+
+	if strings.HasSuffix(path, ".bz2") {
+
+		bz_wr, err = bzip2.NewWriter(wr, nil)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create bzip2 writer, %w", err)
+		}
+
+		out = bz_wr
+	}
+
+	monitor, err := timings.NewMonitor(ctx, monitor_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create monitor, %w", err)
+	}
+
+	monitor.Start(ctx, os.Stderr)
+	defer monitor.Stop(ctx)
+
+	enc := json.NewEncoder(out)
+	count := 0
+
+	iter_cb := func(ctx context.Context, loc *location.Location) error {
+
+		var v interface{} = loc
+
+		if format == FORMAT_GEOJSONL {
+
+			f, err := loc.AsFeature()
+
+			if err != nil {
+				return fmt.Errorf("Failed to derive feature for %s, %w", loc.ID, err)
+			}
+
+			v = f
+		}
+
+		err := enc.Encode(v)
+
+		if err != nil {
+			return fmt.Errorf("Failed to encode %s, %w", loc.ID, err)
+		}
+
+		count += 1
+		monitor.Signal(ctx)
+		return nil
+	}
+
+	err = db.Iterate(ctx, iter_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to export locations, %w", err)
+	}
+
+	if bz_wr != nil {
+
+		err = bz_wr.Close()
+
+		if err != nil {
+			return fmt.Errorf("Failed to close bzip2 writer, %w", err)
+		}
+	}
+
+	if path != "-" {
+
+		err = wr.Close()
+
+		if err != nil {
+			return fmt.Errorf("Failed to close writer for %s, %w", path, err)
+		}
+	}
+
+	slog.Info("Exported locations", "count", count, "path", path)
+	return nil
+}
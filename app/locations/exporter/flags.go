@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sfomuseum/go-flags/flagset"
+)
+
+var location_database_uri string
+var bucket_uri string
+var format string
+
+var monitor_uri string
+
+var verbose bool
+
+func DefaultFlagSet() *flag.FlagSet {
+
+	fs := flagset.NewFlagSet("export")
+
+	fs.StringVar(&location_database_uri, "location-database-uri", "", "A valid whosonfirst/go-dedupe/location.Database URI.")
+	fs.StringVar(&bucket_uri, "bucket-uri", "cwd://", "A valid gocloud.dev/blob.Bucket URI where the export file will be written.")
+	fs.StringVar(&format, "format", FORMAT_JSONL, "The format to export locations in. Valid options are: jsonl (one JSON-encoded location per line), geojsonl (one GeoJSON Feature per line).")
+
+	fs.StringVar(&monitor_uri, "monitor-uri", "counter://PT60S", "A valid sfomuseum/go-timings.Monitor URI.")
+	fs.BoolVar(&verbose, "verbose", false, "Enable verbose (debug) logging.")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Export the records in a location database as newline-delimited JSON or GeoJSON.\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\t %s [options] path\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Where \"path\" is the name of the file to write, relative to -bucket-uri, or \"-\" to write to STDOUT. If \"path\" ends in \".bz2\" the output is bzip2-compressed.\n")
+		fmt.Fprintf(os.Stderr, "Valid options are:\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
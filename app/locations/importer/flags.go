@@ -0,0 +1,41 @@
+package importer
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/sfomuseum/go-flags/flagset"
+)
+
+var location_database_uri string
+var bucket_uri string
+
+var batch_size int
+
+var monitor_uri string
+
+var verbose bool
+
+func DefaultFlagSet() *flag.FlagSet {
+
+	fs := flagset.NewFlagSet("import")
+
+	fs.StringVar(&location_database_uri, "location-database-uri", "", "A valid whosonfirst/go-dedupe/location.Database URI.")
+	fs.StringVar(&bucket_uri, "bucket-uri", "cwd://", "A valid gocloud.dev/blob.Bucket URI where the files to import will be read from.")
+
+	fs.IntVar(&batch_size, "batch-size", 1000, "The number of locations to add at once if the location database supports batching. If less than 2 locations are added one at a time.")
+
+	fs.StringVar(&monitor_uri, "monitor-uri", "counter://PT60S", "A valid sfomuseum/go-timings.Monitor URI.")
+	fs.BoolVar(&verbose, "verbose", false, "Enable verbose (debug) logging.")
+
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Import newline-delimited JSON or GeoJSON locations, produced by the export-locations tool, in to a location database.\n")
+		fmt.Fprintf(os.Stderr, "Usage:\n\t %s [options] path(N) path(N)\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Where \"path\" is the name of a file to read, relative to -bucket-uri, or \"-\" to read from STDIN. Files ending in \".bz2\" are assumed to be bzip2-compressed.\n")
+		fmt.Fprintf(os.Stderr, "Valid options are:\n")
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
@@ -0,0 +1,224 @@
+package importer
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/aaronland/gocloud-blob/bucket"
+	"github.com/sfomuseum/go-flags/flagset"
+	"github.com/sfomuseum/go-timings"
+	"github.com/whosonfirst/go-dedupe/location"
+	"gocloud.dev/blob"
+)
+
+func Run(ctx context.Context) error {
+	fs := DefaultFlagSet()
+	return RunWithFlagSet(ctx, fs)
+}
+
+func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
+
+	flagset.Parse(fs)
+
+	if verbose {
+		slog.SetLogLoggerLevel(slog.LevelDebug)
+		slog.Debug("Verbose logging enabled")
+	}
+
+	paths := fs.Args()
+
+	db, err := location.NewDatabase(ctx, location_database_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new location database, %w", err)
+	}
+
+	defer db.Close(ctx)
+
+	var b *blob.Bucket
+
+	for _, path := range paths {
+
+		if path == "-" {
+			continue
+		}
+
+		b, err = bucket.OpenBucket(ctx, bucket_uri)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open bucket, %w", err)
+		}
+
+		defer b.Close()
+		break
+	}
+
+	monitor, err := timings.NewMonitor(ctx, monitor_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create monitor, %w", err)
+	}
+
+	monitor.Start(ctx, os.Stderr)
+	defer monitor.Stop(ctx)
+
+	batch_db, use_batches := db.(location.BatchDatabase)
+
+	if batch_size < 2 {
+		use_batches = false
+	}
+
+	batch := make([]*location.Location, 0)
+
+	flush := func(ctx context.Context) error {
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := batch_db.AddLocations(ctx, batch)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add batch of locations, %w", err)
+		}
+
+		slog.Debug("Added batch of locations", "count", len(batch))
+
+		for i := 0; i < len(batch); i++ {
+			monitor.Signal(ctx)
+		}
+
+		batch = make([]*location.Location, 0)
+		return nil
+	}
+
+	add := func(ctx context.Context, loc *location.Location) error {
+
+		if use_batches {
+
+			batch = append(batch, loc)
+
+			if len(batch) < batch_size {
+				return nil
+			}
+
+			return flush(ctx)
+		}
+
+		err := db.AddLocation(ctx, loc)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add location %s, %w", loc.ID, err)
+		}
+
+		monitor.Signal(ctx)
+		return nil
+	}
+
+	count := 0
+
+	for _, path := range paths {
+
+		var r io.ReadCloser
+
+		if path == "-" {
+			r = os.Stdin
+		} else {
+
+			bucket_r, err := b.NewReader(ctx, path, nil)
+
+			if err != nil {
+				return fmt.Errorf("Failed to create new reader for %s, %w", path, err)
+			}
+
+			r = bucket_r
+		}
+
+		n, err := importLocations(ctx, r, strings.HasSuffix(path, ".bz2"), add)
+
+		if path != "-" {
+			r.Close()
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to import locations from %s, %w", path, err)
+		}
+
+		slog.Debug("Imported locations", "path", path, "count", n)
+		count += n
+	}
+
+	if use_batches {
+
+		err := flush(ctx)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	slog.Info("Imported locations", "count", count)
+	return nil
+}
+
+// importLocations reads newline-delimited JSON-encoded locations or GeoJSON Features from 'r', optionally
+// decompressing it, and invokes 'cb' for each one. It returns the number of locations read.
+func importLocations(ctx context.Context, r io.Reader, is_bzip bool, cb func(context.Context, *location.Location) error) (int, error) {
+
+	if is_bzip {
+		r = bzip2.NewReader(bufio.NewReader(r))
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	count := 0
+	lineno := 0
+
+	for scanner.Scan() {
+
+		lineno += 1
+		body := scanner.Bytes()
+
+		if len(body) == 0 {
+			continue
+		}
+
+		var loc *location.Location
+		var err error
+
+		if location.IsFeature(body) {
+			loc, err = location.NewLocationFromFeature(body)
+		} else {
+			err = json.Unmarshal(body, &loc)
+		}
+
+		if err != nil {
+			return count, fmt.Errorf("Failed to decode location at line %d, %w", lineno, err)
+		}
+
+		err = cb(ctx, loc)
+
+		if err != nil {
+			return count, err
+		}
+
+		count += 1
+	}
+
+	err := scanner.Err()
+
+	if err != nil {
+		return count, fmt.Errorf("Failed to read locations, %w", err)
+	}
+
+	return count, nil
+}
@@ -12,6 +12,8 @@ var location_database_uri string
 var location_parser_uri string
 var iterator_uri string
 
+var batch_size int
+
 var monitor_uri string
 
 var verbose bool
@@ -24,6 +26,8 @@ func DefaultFlagSet() *flag.FlagSet {
 	fs.StringVar(&location_parser_uri, "location-parser-uri", "", "A valid whosonfirst/go-dedupe/location.Parser URI.")
 	fs.StringVar(&iterator_uri, "iterator-uri", "", "A valid whosonfirst/go-dedupe/iterator.Iterator URI.")
 
+	fs.IntVar(&batch_size, "batch-size", 1000, "The number of locations to add at once if the location database supports batching. If less than 2 locations are added one at a time.")
+
 	fs.StringVar(&monitor_uri, "monitor-uri", "counter://PT60S", "A valid sfomuseum/go-timings.Monitor URI.")
 	fs.BoolVar(&verbose, "verbose", false, "Enable verbose (debug) logging.")
 
@@ -1,11 +1,13 @@
 package index
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
 	"log/slog"
 	"os"
+	"sync"
 
 	"github.com/sfomuseum/go-flags/flagset"
 	"github.com/sfomuseum/go-timings"
@@ -68,6 +70,48 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 	monitor.Start(ctx, os.Stderr)
 	defer monitor.Stop(ctx)
 
+	// If the database supports it add locations in batches rather than one at a time. Iterators may invoke
+	// their callback functions concurrently so the buffer of pending locations is guarded by a mutex.
+
+	batch_db, use_batches := db.(location.BatchDatabase)
+
+	if batch_size < 2 {
+		use_batches = false
+	}
+
+	// Only keep a copy of each record's source feature if the database is going to store it.
+
+	store_sources := false
+
+	if src_db, ok := db.(location.SourceDatabase); ok {
+		store_sources = src_db.StoresSources()
+	}
+
+	batch := make([]*location.Location, 0)
+	batch_mu := new(sync.Mutex)
+
+	flush := func(ctx context.Context) error {
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		err := batch_db.AddLocations(ctx, batch)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add batch of locations, %w", err)
+		}
+
+		slog.Debug("Added batch of locations", "count", len(batch))
+
+		for i := 0; i < len(batch); i++ {
+			monitor.Signal(ctx)
+		}
+
+		batch = make([]*location.Location, 0)
+		return nil
+	}
+
 	iter_cb := func(ctx context.Context, body []byte) error {
 
 		loc, err := prsr.Parse(ctx, body)
@@ -80,6 +124,33 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 			return fmt.Errorf("Failed to parse body, %w", err)
 		}
 
+		// Iterators may reuse the buffer for 'body' so keep a copy of it
+
+		if store_sources {
+			loc.Feature = bytes.Clone(body)
+		}
+
+		if use_batches {
+
+			batch_mu.Lock()
+			defer batch_mu.Unlock()
+
+			batch = append(batch, loc)
+
+			if len(batch) < batch_size {
+				return nil
+			}
+
+			err := flush(ctx)
+
+			if err != nil {
+				slog.Error("Failed to add records", "error", err)
+				return err
+			}
+
+			return nil
+		}
+
 		err = db.AddLocation(ctx, loc)
 
 		if err != nil {
@@ -99,5 +170,17 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 		return fmt.Errorf("Failed to walk, %v", err)
 	}
 
+	if use_batches {
+
+		batch_mu.Lock()
+		defer batch_mu.Unlock()
+
+		err := flush(ctx)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -7,11 +7,11 @@ import (
 	"io"
 	"log/slog"
 	"strconv"
-	"strings"
 
 	"github.com/sfomuseum/go-csvdict"
 	"github.com/sfomuseum/go-edtf"
 	"github.com/sfomuseum/go-flags/flagset"
+	"github.com/whosonfirst/go-dedupe"
 	"github.com/whosonfirst/go-reader"
 	"github.com/whosonfirst/go-whosonfirst-export/v2"
 	"github.com/whosonfirst/go-whosonfirst-feature/properties"
@@ -46,8 +46,6 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 		return fmt.Errorf("Failed to create new writer, %w", err)
 	}
 
-	concordance_key := fmt.Sprintf("%s:%s", concordance_namespace, concordance_predicate)
-
 	concordances := fs.Args()
 
 	for _, path := range concordances {
@@ -71,35 +69,37 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 			logger = logger.With("wof label", wof_label)
 
 			var str_wof_id string
-			var other_id string
+			var str_other_id string
 			var other_label string
 
 			switch wof_label {
 			case "target":
 				str_wof_id = row["target_id"]
-				other_id = row["source_id"]
+				str_other_id = row["source_id"]
 				other_label = row["source"]
 
 			default:
 				str_wof_id = row["source_id"]
-				other_id = row["target_id"]
+				str_other_id = row["target_id"]
 				other_label = row["target"]
 			}
 
-			// Please make this less bad
-			other_parts := strings.Split(other_id, "=")
-			other_id = other_parts[1]
+			wof_id, err := dedupe.ParseWhosOnFirstId(str_wof_id)
 
-			// Please make this less bad
-			str_wof_id = strings.Replace(str_wof_id, "wof:id=", "", 1)
+			if err != nil {
+				logger.Warn("Failed to parse WOF ID, skipping", "error", err)
+				continue
+			}
 
-			wof_id, err := strconv.ParseInt(str_wof_id, 10, 64)
+			other_id, namespace, err := parseOtherID(str_other_id, concordance_namespace)
 
 			if err != nil {
-				logger.Warn("Failed to parse WOF ID, skipping", "error", err)
+				logger.Warn("Failed to parse other ID, skipping", "error", err)
 				continue
 			}
 
+			concordance_key := fmt.Sprintf("%s:%s", namespace, concordance_predicate)
+
 			logger = logger.With("wof id", wof_id)
 			logger = logger.With("other id", other_id)
 
@@ -125,8 +125,8 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 			}
 
 			updates := map[string]interface{}{
-				"properties.wof:concordances":                             concordances,
-				fmt.Sprintf("properties.%s:label", concordance_namespace): other_label,
+				"properties.wof:concordances":                 concordances,
+				fmt.Sprintf("properties.%s:label", namespace): other_label,
 			}
 
 			if mark_is_current {
@@ -140,12 +140,17 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 				}
 			}
 
-			similarity, err := strconv.ParseFloat(row["similarity"], 32)
+			// Exact matches (for example a shared phone number) have an empty similarity value
 
-			if err != nil {
-				logger.Error("Failed to parse similarity as float, ignoring", "similarity", row["similarity"], "error", err)
-			} else {
-				updates[fmt.Sprintf("properties.%s:similarity", concordance_namespace)] = similarity
+			if row["similarity"] != "" {
+
+				similarity, err := strconv.ParseFloat(row["similarity"], 32)
+
+				if err != nil {
+					logger.Error("Failed to parse similarity as float, ignoring", "similarity", row["similarity"], "error", err)
+				} else {
+					updates[fmt.Sprintf("properties.%s:similarity", namespace)] = similarity
+				}
 			}
 
 			has_changes, new_body, err := export.AssignPropertiesIfChanged(ctx, body, updates)
@@ -172,3 +177,32 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 
 	return nil
 }
+
+// parseOtherID parses the (non-WOF) ID 'str' and returns its value and the concordance namespace to store it under.
+// If 'namespace' is not empty it is returned as-is. Otherwise the namespace registered for the ID's prefix is used,
+// or the prefix itself if it has not been registered in this process (for example a custom prefix registered by the
+// csvmapped:// or jsonpath:// parsers when the locations were indexed).
+func parseOtherID(str string, namespace string) (string, string, error) {
+
+	id, err := dedupe.ParseUnregisteredID(str)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	if namespace != "" {
+		return id.Value, namespace, nil
+	}
+
+	if !dedupe.IsRegisteredIDPrefix(id.Prefix) {
+		return id.Value, id.Prefix, nil
+	}
+
+	ns, err := id.ConcordanceNamespace()
+
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to derive concordance namespace, %w", err)
+	}
+
+	return id.Value, ns, nil
+}
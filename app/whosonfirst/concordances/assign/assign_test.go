@@ -0,0 +1,40 @@
+package assign
+
+import (
+	"testing"
+)
+
+func TestParseOtherID(t *testing.T) {
+
+	tests := []struct {
+		str       string
+		namespace string
+		value     string
+		expected  string
+	}{
+		{"ovtr:id=08f2a1", "", "08f2a1", "ovtr"},
+		{"ovtr:id=08f2a1", "overture", "08f2a1", "overture"},
+		// Custom prefixes registered by another process (for example when indexing locations)
+		{"acme:id=1234", "", "1234", "acme"},
+		{"acme:id=1234", "acmeco", "1234", "acmeco"},
+	}
+
+	for _, test := range tests {
+
+		value, namespace, err := parseOtherID(test.str, test.namespace)
+
+		if err != nil {
+			t.Fatalf("Failed to parse '%s', %v", test.str, err)
+		}
+
+		if value != test.value || namespace != test.expected {
+			t.Fatalf("Unexpected result parsing '%s' (%s): %s %s", test.str, test.namespace, value, namespace)
+		}
+	}
+
+	_, _, err := parseOtherID("acme:1234", "")
+
+	if err == nil {
+		t.Fatalf("Expected invalid ID to fail parsing")
+	}
+}
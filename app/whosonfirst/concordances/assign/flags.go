@@ -29,7 +29,7 @@ func DefaultFlagSet() *flag.FlagSet {
 
 	fs.StringVar(&wof_label, "whosonfirst-label", "target", "The \"label\" used to identify WOF records. Valid options are: source, target.")
 
-	fs.StringVar(&concordance_namespace, "concordance-namespace", "", "The namespace of the concordance being applied.")
+	fs.StringVar(&concordance_namespace, "concordance-namespace", "", "The namespace of the concordance being applied. If empty the namespace registered (using dedupe.RegisterIDPrefix) for the prefix of each non-WOF ID will be used or, if the prefix has not been registered, the prefix itself.")
 	fs.StringVar(&concordance_predicate, "concordance-predicate", "id", "The predicate of the concordance being applies.")
 	fs.BoolVar(&concordance_as_int, "concordance-as-int", false, "If true cast the concordance ID as an int64")
 
@@ -74,7 +74,7 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 		err = os.Remove(path)
 
 		if err != nil {
-			return fmt.Errorf("Failed to remove record", "path", path)
+			return fmt.Errorf("Failed to remove record %s, %w", path, err)
 		}
 
 		return nil
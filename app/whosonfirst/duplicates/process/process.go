@@ -53,8 +53,6 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 		return fmt.Errorf("Failed to create new writer, %w", err)
 	}
 
-	id_prefix := fmt.Sprintf("%s:id=", dedupe.WHOSONFIRST_PREFIX)
-
 	dupes := new(sync.Map)
 
 	for _, path := range csv_dupes {
@@ -105,20 +103,17 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 				continue
 			}
 
-			str_source := strings.Replace(ns_source, id_prefix, "", 1)
-			str_target := strings.Replace(ns_target, id_prefix, "", 1)
-
-			source_id, err := strconv.ParseInt(str_source, 10, 64)
+			source_id, err := dedupe.ParseWhosOnFirstId(ns_source)
 
 			if err != nil {
-				slog.Error("Failed to parse source ID, skipping", "id", str_source, "id (ns)", ns_source, "error", err)
+				slog.Error("Failed to parse source ID, skipping", "id (ns)", ns_source, "error", err)
 				continue
 			}
 
-			target_id, err := strconv.ParseInt(str_target, 10, 64)
+			target_id, err := dedupe.ParseWhosOnFirstId(ns_target)
 
 			if err != nil {
-				slog.Error("Failed to parse target ID, skipping", "id", str_target, "id (ns)", ns_target, "error", err)
+				slog.Error("Failed to parse target ID, skipping", "id (ns)", ns_target, "error", err)
 				continue
 			}
 
@@ -262,11 +257,14 @@ func RunWithFlagSet(ctx context.Context, fs *flag.FlagSet) error {
 			var invalid_id int64
 			var invalid_f []byte
 
-			source_geom := gjson.GetBytes(source_f, "properties.src:geom").String()
-			target_geom := gjson.GetBytes(target_f, "properties.src:geom").String()
+			// Prefer provenance properties in the (current) WOF records, falling back to the properties included
+			// in the compare output which may be stale by the time duplicates are processed
+
+			source_geom := provenanceString(source_f, "properties.src:geom", row, "source_geometry_source")
+			target_geom := provenanceString(target_f, "properties.src:geom", row, "target_geometry_source")
 
-			source_lastmod := properties.LastModified(source_f)
-			target_lastmod := properties.LastModified(target_f)
+			source_lastmod := provenanceInt(source_f, "properties.wof:lastmodified", row, "source_lastmodified")
+			target_lastmod := provenanceInt(target_f, "properties.wof:lastmodified", row, "target_lastmodified")
 
 			if source_geom == "mapzen" || target_geom == "mapzen" {
 
@@ -401,3 +399,39 @@ func write_updates(ctx context.Context, wr writer.Writer, body []byte, updates m
 
 	return nil
 }
+
+// provenanceString returns the value of 'path' in 'body' if present and not empty, otherwise it returns the value of 'col' in 'row'.
+func provenanceString(body []byte, path string, row map[string]string, col string) string {
+
+	rsp := gjson.GetBytes(body, path)
+
+	if rsp.Exists() && rsp.String() != "" {
+		return rsp.String()
+	}
+
+	return row[col]
+}
+
+// provenanceInt returns the value of 'path' in 'body', as an int64, if present, otherwise it returns the value of 'col' in 'row'
+// if present and valid.
+func provenanceInt(body []byte, path string, row map[string]string, col string) int64 {
+
+	rsp := gjson.GetBytes(body, path)
+
+	if rsp.Exists() {
+		return rsp.Int()
+	}
+
+	v, exists := row[col]
+
+	if exists && v != "" {
+
+		i, err := strconv.ParseInt(v, 10, 64)
+
+		if err == nil {
+			return i
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,106 @@
+// Package category provides methods for normalizing the (venue) categories assigned by different data
+// sources into a small set of broad categories and for determining whether two categories are compatible
+// with one another for the purposes of deduplication.
+package category
+
+import (
+	"regexp"
+	"strings"
+)
+
+// The list of normalized categories.
+const (
+	UNKNOWN    string = ""
+	AUTOMOTIVE string = "automotive"
+	CULTURE    string = "culture"
+	EDUCATION  string = "education"
+	FINANCE    string = "finance"
+	FOOD       string = "food"
+	GOVERNMENT string = "government"
+	HEALTH     string = "health"
+	LODGING    string = "lodging"
+	RECREATION string = "recreation"
+	RELIGION   string = "religion"
+	SERVICES   string = "services"
+	SHOPPING   string = "shopping"
+	TRANSPORT  string = "transport"
+)
+
+var re_separator = regexp.MustCompile(`[^\pL\pN]+`)
+
+// Normalize returns the normalized category for 'raw' which may be a category or placetype string from any of
+// the supported data sources, for example "pizza_restaurant" (Overture), "dentist" (OSM tags used by All The Places),
+// "Food & Drink" (SimpleGeo classifiers) or "museum". Strings are first compared in their entirety and then word by
+// word, from last to first (with generic words like "shop" or "store" considered last), so that "pizza_restaurant" and "dental_clinic" yield "food" and "health" respectively.
+// If 'raw' can not be mapped to a category then `UNKNOWN` (an empty string) is returned.
+func Normalize(raw string) string {
+
+	k := strings.ToLower(strings.TrimSpace(raw))
+	k = re_separator.ReplaceAllString(k, "_")
+	k = strings.Trim(k, "_")
+
+	if k == "" {
+		return UNKNOWN
+	}
+
+	c, exists := keywords[k]
+
+	if exists {
+		return c
+	}
+
+	words := strings.Split(k, "_")
+
+	// Pairs of words, like "ice_cream" or "fast_food"
+
+	for i := len(words) - 1; i > 0; i-- {
+
+		c, exists := keywords[words[i-1]+"_"+words[i]]
+
+		if exists {
+			return c
+		}
+	}
+
+	// Individual words, like "restaurant" or "clinic", ignoring generic words like "shop" or "store"
+	// so that "coffee_shop" yields "food" rather than "shopping"
+
+	for _, ignore_generic := range []bool{true, false} {
+
+		for i := len(words) - 1; i >= 0; i-- {
+
+			if ignore_generic && isGeneric(words[i]) {
+				continue
+			}
+
+			c, exists := keywords[words[i]]
+
+			if exists {
+				return c
+			}
+		}
+	}
+
+	return UNKNOWN
+}
+
+// Compatible returns a boolean value indicating whether two normalized categories are compatible with one
+// another; that is whether two venues with categories 'a' and 'b' might plausibly be the same venue. Categories
+// are compatible if they are the same, if either category is `UNKNOWN` or if they are listed as compatible in
+// the compatibility matrix. For example "food" and "shopping" are compatible (a deli versus a grocery store) but
+// "health" and "food" (a dentist versus a pizzeria) are not.
+func Compatible(a string, b string) bool {
+
+	if a == UNKNOWN || b == UNKNOWN || a == b {
+		return true
+	}
+
+	for _, pair := range compatible {
+
+		if (pair[0] == a && pair[1] == b) || (pair[0] == b && pair[1] == a) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package category
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+
+	tests := map[string]string{
+		"pizza_restaurant":   FOOD,
+		"Restaurant":         FOOD,
+		"Food & Drink":       FOOD,
+		"coffee_shop":        FOOD,
+		"ice_cream_shop":     FOOD,
+		"dentist":            HEALTH,
+		"dental_clinic":      HEALTH,
+		"art_museum":         CULTURE,
+		"historical_society": CULTURE,
+		"supermarket":        SHOPPING,
+		"shoe_store":         SHOPPING,
+		"place_of_worship":   RELIGION,
+		"venue":              UNKNOWN,
+		"":                   UNKNOWN,
+	}
+
+	for raw, expected := range tests {
+
+		c := Normalize(raw)
+
+		if c != expected {
+			t.Fatalf("Unexpected category for '%s'. Expected '%s' but got '%s'", raw, expected, c)
+		}
+	}
+}
+
+func TestCompatible(t *testing.T) {
+
+	tests := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{FOOD, FOOD, true},
+		{FOOD, UNKNOWN, true},
+		{UNKNOWN, HEALTH, true},
+		{FOOD, SHOPPING, true},
+		{SHOPPING, FOOD, true},
+		{HEALTH, FOOD, false},
+		{FINANCE, RELIGION, false},
+	}
+
+	for _, test := range tests {
+
+		ok := Compatible(test.a, test.b)
+
+		if ok != test.expected {
+			t.Fatalf("Unexpected result comparing '%s' and '%s'. Expected %t", test.a, test.b, test.expected)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package category
+
+// The lookup tables in this file are not meant to be exhaustive. They cover the category names and
+// placetypes most commonly encountered in the data sources supported by this package.
+
+// keywords maps (lower-cased) category names, or words in category names, to their normalized category.
+var keywords = map[string]string{
+	// automotive
+	"auto":        AUTOMOTIVE,
+	"automotive":  AUTOMOTIVE,
+	"car":         AUTOMOTIVE,
+	"car_dealer":  AUTOMOTIVE,
+	"car_rental":  AUTOMOTIVE,
+	"car_repair":  AUTOMOTIVE,
+	"car_wash":    AUTOMOTIVE,
+	"fuel":        AUTOMOTIVE,
+	"gas_station": AUTOMOTIVE,
+	"mechanic":    AUTOMOTIVE,
+	"parking":     AUTOMOTIVE,
+	"tyres":       AUTOMOTIVE,
+	// culture
+	"aquarium":           CULTURE,
+	"arboretum":          CULTURE,
+	"art_museum":         CULTURE,
+	"arts_centre":        CULTURE,
+	"botanical_garden":   CULTURE,
+	"gallery":            CULTURE,
+	"historical_society": CULTURE,
+	"library":            CULTURE,
+	"museum":             CULTURE,
+	"planetarium":        CULTURE,
+	"theater":            CULTURE,
+	"theatre":            CULTURE,
+	"zoo":                CULTURE,
+	// education
+	"academy":      EDUCATION,
+	"college":      EDUCATION,
+	"education":    EDUCATION,
+	"kindergarten": EDUCATION,
+	"preschool":    EDUCATION,
+	"school":       EDUCATION,
+	"university":   EDUCATION,
+	// finance
+	"accountant":   FINANCE,
+	"atm":          FINANCE,
+	"bank":         FINANCE,
+	"credit_union": FINANCE,
+	"finance":      FINANCE,
+	"financial":    FINANCE,
+	"insurance":    FINANCE,
+	// food
+	"bakery":     FOOD,
+	"bar":        FOOD,
+	"bistro":     FOOD,
+	"brewery":    FOOD,
+	"burger":     FOOD,
+	"cafe":       FOOD,
+	"coffee":     FOOD,
+	"deli":       FOOD,
+	"diner":      FOOD,
+	"drink":      FOOD,
+	"fast_food":  FOOD,
+	"food":       FOOD,
+	"food_court": FOOD,
+	"ice_cream":  FOOD,
+	"pizza":      FOOD,
+	"pizzeria":   FOOD,
+	"pub":        FOOD,
+	"restaurant": FOOD,
+	"steakhouse": FOOD,
+	"sushi":      FOOD,
+	"takeaway":   FOOD,
+	"winery":     FOOD,
+	// government
+	"courthouse":   GOVERNMENT,
+	"embassy":      GOVERNMENT,
+	"fire_station": GOVERNMENT,
+	"government":   GOVERNMENT,
+	"police":       GOVERNMENT,
+	"post_office":  GOVERNMENT,
+	"townhall":     GOVERNMENT,
+	// health
+	"chiropractor": HEALTH,
+	"clinic":       HEALTH,
+	"dental":       HEALTH,
+	"dentist":      HEALTH,
+	"doctor":       HEALTH,
+	"doctors":      HEALTH,
+	"health":       HEALTH,
+	"healthcare":   HEALTH,
+	"hospital":     HEALTH,
+	"medical":      HEALTH,
+	"optician":     HEALTH,
+	"optometrist":  HEALTH,
+	"pharmacy":     HEALTH,
+	"physician":    HEALTH,
+	"veterinary":   HEALTH,
+	// lodging
+	"accommodation":     LODGING,
+	"bed_and_breakfast": LODGING,
+	"guest_house":       LODGING,
+	"hostel":            LODGING,
+	"hotel":             LODGING,
+	"inn":               LODGING,
+	"lodging":           LODGING,
+	"motel":             LODGING,
+	"resort":            LODGING,
+	// recreation
+	"cinema":         RECREATION,
+	"fitness":        RECREATION,
+	"fitness_centre": RECREATION,
+	"golf":           RECREATION,
+	"gym":            RECREATION,
+	"leisure":        RECREATION,
+	"park":           RECREATION,
+	"playground":     RECREATION,
+	"sports":         RECREATION,
+	"stadium":        RECREATION,
+	"swimming_pool":  RECREATION,
+	// religion
+	"church":           RELIGION,
+	"mosque":           RELIGION,
+	"place_of_worship": RELIGION,
+	"religion":         RELIGION,
+	"religious":        RELIGION,
+	"synagogue":        RELIGION,
+	"temple":           RELIGION,
+	// services
+	"barber":       SERVICES,
+	"beauty":       SERVICES,
+	"dry_cleaning": SERVICES,
+	"hairdresser":  SERVICES,
+	"laundry":      SERVICES,
+	"lawyer":       SERVICES,
+	"legal":        SERVICES,
+	"real_estate":  SERVICES,
+	"salon":        SERVICES,
+	"services":     SERVICES,
+	"spa":          SERVICES,
+	"tailor":       SERVICES,
+	// shopping
+	"alcohol":          SHOPPING,
+	"books":            SHOPPING,
+	"boutique":         SHOPPING,
+	"clothes":          SHOPPING,
+	"convenience":      SHOPPING,
+	"department_store": SHOPPING,
+	"electronics":      SHOPPING,
+	"florist":          SHOPPING,
+	"furniture":        SHOPPING,
+	"gift":             SHOPPING,
+	"grocery":          SHOPPING,
+	"hardware":         SHOPPING,
+	"jewelry":          SHOPPING,
+	"liquor":           SHOPPING,
+	"mall":             SHOPPING,
+	"retail":           SHOPPING,
+	"shoes":            SHOPPING,
+	"shop":             SHOPPING,
+	"shopping":         SHOPPING,
+	"store":            SHOPPING,
+	"supermarket":      SHOPPING,
+	// transport
+	"airport":        TRANSPORT,
+	"bus_station":    TRANSPORT,
+	"ferry_terminal": TRANSPORT,
+	"station":        TRANSPORT,
+	"transport":      TRANSPORT,
+	"transit":        TRANSPORT,
+}
+
+// generic is the list of (lower-cased) words which only indicate a category in the absence of any more specific words.
+var generic = map[string]bool{
+	"center":   true,
+	"centre":   true,
+	"services": true,
+	"shop":     true,
+	"shopping": true,
+	"store":    true,
+}
+
+// compatible is the list of pairs of (different) normalized categories which are considered compatible with one another.
+var compatible = [][2]string{
+	// A deli versus a grocery store
+	{FOOD, SHOPPING},
+	// A hotel restaurant or bar
+	{FOOD, LODGING},
+	// A bowling alley or a stadium concession
+	{FOOD, RECREATION},
+	// A museum cafe
+	{FOOD, CULTURE},
+	// A pharmacy versus a drug store
+	{HEALTH, SHOPPING},
+	// A spa or a physiotherapist
+	{HEALTH, SERVICES},
+	// A repair shop versus a store
+	{SERVICES, SHOPPING},
+	// A tire store versus a garage
+	{AUTOMOTIVE, SHOPPING},
+	{AUTOMOTIVE, SERVICES},
+	// A university museum or library
+	{CULTURE, EDUCATION},
+	// A public library or historic city hall
+	{CULTURE, GOVERNMENT},
+	// A park, zoo or botanical garden
+	{CULTURE, RECREATION},
+	// A resort
+	{LODGING, RECREATION},
+	// A church school
+	{EDUCATION, RELIGION},
+	// A cathedral as a tourist attraction
+	{CULTURE, RELIGION},
+	// A post office counter in a store
+	{GOVERNMENT, SHOPPING},
+}
+
+func isGeneric(k string) bool {
+	_, exists := generic[k]
+	return exists
+}
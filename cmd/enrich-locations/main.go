@@ -0,0 +1,27 @@
+package main
+
+/*
+
+> go run cmd/enrich-locations/main.go -location-database-uri 'sql://sqlite3?dsn=/usr/local/data/overture-ca.db' /usr/local/data/whosonfirst-data-admin-us/
+
+*/
+
+import (
+	"context"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+	_ "github.com/whosonfirst/go-dedupe/whosonfirst"
+
+	"github.com/whosonfirst/go-dedupe/app/locations/enrich"
+)
+
+func main() {
+
+	ctx := context.Background()
+	err := enrich.Run(ctx)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
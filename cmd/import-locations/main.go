@@ -0,0 +1,27 @@
+package main
+
+/*
+
+> go run cmd/import-locations/main.go -location-database-uri 'sql://sqlite3?dsn=/usr/local/data/overture-ca.db' overture-ca.jsonl.bz2
+
+*/
+
+import (
+	"context"
+	"log"
+
+	_ "github.com/mattn/go-sqlite3"
+	_ "gocloud.dev/blob/fileblob"
+
+	"github.com/whosonfirst/go-dedupe/app/locations/importer"
+)
+
+func main() {
+
+	ctx := context.Background()
+	err := importer.Run(ctx)
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -12,7 +12,10 @@ import (
 
 	_ "github.com/mattn/go-sqlite3"
 	_ "github.com/whosonfirst/go-dedupe/alltheplaces"
+	_ "github.com/whosonfirst/go-dedupe/csvmapped"
 	_ "github.com/whosonfirst/go-dedupe/ilms"
+	_ "github.com/whosonfirst/go-dedupe/jsonpath"
+	_ "github.com/whosonfirst/go-dedupe/openstreetmap"
 	_ "github.com/whosonfirst/go-dedupe/overture"
 	_ "github.com/whosonfirst/go-dedupe/whosonfirst"
 
@@ -14,9 +14,12 @@ import (
 	"sync"
 	"time"
 
+	mmgeohash "github.com/mmcloughlin/geohash"
 	"github.com/sfomuseum/go-csvdict"
 	"github.com/sfomuseum/go-timings"
 	"github.com/whosonfirst/go-dedupe/location"
+	// CompareLocationDatabases writes the locations for each block to temporary files which are read using file:// bucket URIs.
+	_ "gocloud.dev/blob/fileblob"
 )
 
 type CompareLocationDatabasesOptions struct {
@@ -26,6 +29,17 @@ type CompareLocationDatabasesOptions struct {
 	MonitorURI                string
 	Threshold                 float64
 	Workers                   int
+	// If true then candidate matches whose (normalized) categories are not compatible are rejected.
+	CheckCategories bool
+	// If true then source locations in the 8 geohashes neighbouring each target geohash are included when
+	// building the vector database for that geohash.
+	IncludeNeighbors bool
+	// If true then target locations in each geohash are grouped by their Who's On First region and only compared
+	// with source locations in the same region. Locations whose region is not known are compared with all the
+	// locations in a geohash.
+	BlockOnRegion bool
+	// The writer where CSV rows for matching locations are written. If nil then rows are written to STDOUT.
+	Writer io.Writer
 }
 
 func CompareLocationDatabases(ctx context.Context, opts *CompareLocationDatabasesOptions) error {
@@ -92,6 +106,12 @@ func CompareLocationDatabases(ctx context.Context, opts *CompareLocationDatabase
 
 	wg := new(sync.WaitGroup)
 
+	var out io.Writer = os.Stdout
+
+	if opts.Writer != nil {
+		out = opts.Writer
+	}
+
 	var csv_writer *csvdict.Writer
 
 	err_ch := make(chan error)
@@ -112,7 +132,7 @@ func CompareLocationDatabases(ctx context.Context, opts *CompareLocationDatabase
 				fieldnames = append(fieldnames, k)
 			}
 
-			wr, err := csvdict.NewWriter(os.Stdout, fieldnames)
+			wr, err := csvdict.NewWriter(out, fieldnames)
 
 			if err != nil {
 				return fmt.Errorf("Failed to create CSV writer, %w", err)
@@ -177,121 +197,177 @@ func CompareLocationDatabases(ctx context.Context, opts *CompareLocationDatabase
 		}
 	}()
 
-	// Iterate through the list of geohashes dispatching mulitple instances of
-	// CompareLocationsForGeohash in Go routines
+	// Set up func to compare the source and target locations in a geohash (optionally filtered by region)
 
-	for _, geohash := range geohashes {
+	compare_block := func(ctx context.Context, logger *slog.Logger, geohash string, region_id int64, source_filter LocationFilter, target_filter LocationFilter) {
 
-		<-throttle
+		block := geohash
 
-		wg.Add(1)
+		if region_id != 0 {
+			block = fmt.Sprintf("%s-%d", geohash, region_id)
+		}
 
-		go func(geohash string) {
+		source_suffix := fmt.Sprintf("*-%s-source.jsonl", block)
+		target_suffix := fmt.Sprintf("*-%s-target.jsonl", block)
 
-			defer func() {
-				monitor.Signal(ctx)
-				throttle <- true
-				wg.Done()
-			}()
+		source_wr, err := os.CreateTemp("", source_suffix)
 
-			logger := slog.Default()
-			logger = logger.With("geohash", geohash)
+		if err != nil {
+			logger.Error("Failed to create source writer", "error", err)
+			return
+		}
 
-			logger.Debug("Process geohash")
+		defer source_wr.Close()
 
-			source_suffix := fmt.Sprintf("*-%s-source.jsonl", geohash)
-			target_suffix := fmt.Sprintf("*-%s-target.jsonl", geohash)
+		source_path := source_wr.Name()
+		defer os.Remove(source_path)
 
-			source_wr, err := os.CreateTemp("", source_suffix)
+		target_wr, err := os.CreateTemp("", target_suffix)
 
-			if err != nil {
-				logger.Error("Failed to create source writer", "error", err)
-				return
-			}
+		if err != nil {
+			logger.Error("Failed to create target writer", "error", err)
+			return
+		}
 
-			source_path := source_wr.Name()
-			defer os.Remove(source_path)
+		defer target_wr.Close()
 
-			target_wr, err := os.CreateTemp("", target_suffix)
+		target_path := target_wr.Name()
+		defer os.Remove(target_path)
 
-			if err != nil {
-				logger.Error("Failed to create target writer", "error", err)
-				return
-			}
+		// Source locations on either side of a geohash boundary are only compared with target locations
+		// on the other side if neighbouring geohashes are included. Any pairs which are matched more
+		// than once are removed by the "ids_seen" check above.
 
-			target_path := target_wr.Name()
-			defer os.Remove(target_path)
+		source_geohashes := []string{
+			geohash,
+		}
+
+		if opts.IncludeNeighbors {
+			source_geohashes = append(source_geohashes, mmgeohash.Neighbors(geohash)...)
+		}
+
+		count_source := 0
+
+		for _, source_geohash := range source_geohashes {
 
 			source_opts := &WriteLocationsWithGeohashOptions{
 				Database: source_database,
 				Logger:   logger,
-				Geohash:  geohash,
+				Geohash:  source_geohash,
 				Writer:   source_wr,
 				Label:    "source",
+				Filter:   source_filter,
 			}
 
-			count_source, err := WriteLocationsWithGeohash(ctx, source_opts)
+			count, err := WriteLocationsWithGeohash(ctx, source_opts)
 
 			if err != nil {
-				logger.Error("Failed to write source locations", "error", err)
+				logger.Error("Failed to write source locations", "source geohash", source_geohash, "error", err)
 				return
 			}
 
-			if count_source == 0 {
-				logger.Debug("No source locations match geohash, skipping")
-				return
-			}
+			count_source += count
+		}
 
-			target_opts := &WriteLocationsWithGeohashOptions{
-				Database: target_database,
-				Logger:   logger,
-				Geohash:  geohash,
-				Writer:   target_wr,
-				Label:    "target",
-			}
+		if count_source == 0 {
+			logger.Debug("No source locations match geohash, skipping")
+			return
+		}
 
-			count_target, err := WriteLocationsWithGeohash(ctx, target_opts)
+		target_opts := &WriteLocationsWithGeohashOptions{
+			Database: target_database,
+			Logger:   logger,
+			Geohash:  geohash,
+			Writer:   target_wr,
+			Label:    "target",
+			Filter:   target_filter,
+		}
 
-			if err != nil {
-				logger.Error("Failed to write target locations", "error", err)
-				return
-			}
+		count_target, err := WriteLocationsWithGeohash(ctx, target_opts)
 
-			if count_target == 0 {
-				logger.Debug("No target locations match geohash, skipping")
-				return
-			}
+		if err != nil {
+			logger.Error("Failed to write target locations", "error", err)
+			return
+		}
+
+		if count_target == 0 {
+			logger.Debug("No target locations match geohash, skipping")
+			return
+		}
+
+		source_root := filepath.Dir(source_path)
+		source_fname := filepath.Base(source_path)
+
+		target_root := filepath.Dir(target_path)
+		target_fname := filepath.Base(target_path)
+
+		// TBD – write source/target to buckets; for example if bucket config
+		// in CompareLocationDatabasesOptions not empty then write files there
+		// rather than defining explicit file:// URIs below.
+
+		source_bucket := fmt.Sprintf("file://%s", source_root)
+		target_bucket := fmt.Sprintf("file://%s", target_root)
+
+		logger.Info("Compare locations", "source count", count_source, "target count", count_target)
+
+		compare_opts := &CompareLocationsForGeohashOptions{
+			SourceBucketURI:   source_bucket,
+			SourceLocations:   source_fname,
+			TargetBucketURI:   target_bucket,
+			TargetLocations:   target_fname,
+			VectorDatabaseURI: opts.VectorDatabaseURI,
+			Geohash:           geohash,
+			Region:            region_id,
+			Threshold:         opts.Threshold,
+			CheckCategories:   opts.CheckCategories,
+			RowChannel:        row_ch,
+		}
 
-			source_root := filepath.Dir(source_path)
-			source_fname := filepath.Base(source_path)
+		err = CompareLocationsForGeohash(ctx, compare_opts)
 
-			target_root := filepath.Dir(target_path)
-			target_fname := filepath.Base(target_path)
+		if err != nil {
+			logger.Error("Failed to compare locations", "error", err)
+		}
+	}
+
+	// Iterate through the list of geohashes dispatching mulitple instances of
+	// CompareLocationsForGeohash in Go routines
+
+	for _, geohash := range geohashes {
+
+		<-throttle
+
+		wg.Add(1)
+
+		go func(geohash string) {
 
-			// TBD – write source/target to buckets; for example if bucket config
-			// in CompareLocationDatabasesOptions not empty then write files there
-			// rather than defining explicit file:// URIs below.
+			defer func() {
+				monitor.Signal(ctx)
+				throttle <- true
+				wg.Done()
+			}()
 
-			source_bucket := fmt.Sprintf("file://%s", source_root)
-			target_bucket := fmt.Sprintf("file://%s", target_root)
+			logger := slog.Default()
+			logger = logger.With("geohash", geohash)
 
-			logger.Info("Compare locations", "source count", count_source, "target count", count_target)
+			logger.Debug("Process geohash")
 
-			compare_opts := &CompareLocationsForGeohashOptions{
-				SourceBucketURI:   source_bucket,
-				SourceLocations:   source_fname,
-				TargetBucketURI:   target_bucket,
-				TargetLocations:   target_fname,
-				VectorDatabaseURI: opts.VectorDatabaseURI,
-				Geohash:           geohash,
-				Threshold:         opts.Threshold,
-				RowChannel:        row_ch,
+			if !opts.BlockOnRegion {
+				compare_block(ctx, logger, geohash, 0, nil, nil)
+				return
 			}
 
-			err = CompareLocationsForGeohash(ctx, compare_opts)
+			regions, err := targetRegions(ctx, target_database, geohash)
 
 			if err != nil {
-				logger.Error("Failed to compare locations", "error", err)
+				logger.Error("Failed to derive target regions", "error", err)
+				return
+			}
+
+			for _, region_id := range regions {
+
+				region_logger := logger.With("region", region_id)
+				compare_block(ctx, region_logger, geohash, region_id, sourceRegionFilter(region_id), targetRegionFilter(region_id))
 			}
 
 		}(geohash)
@@ -307,12 +383,17 @@ func CompareLocationDatabases(ctx context.Context, opts *CompareLocationDatabase
 	return nil
 }
 
+// LocationFilter is a function which returns a boolean value indicating whether a location should be included.
+type LocationFilter func(*location.Location) bool
+
 type WriteLocationsWithGeohashOptions struct {
 	Database location.Database
 	Writer   io.Writer
 	Logger   *slog.Logger
 	Geohash  string
 	Label    string
+	// If defined, only locations for which Filter returns true are written.
+	Filter LocationFilter
 }
 
 func WriteLocationsWithGeohash(ctx context.Context, opts *WriteLocationsWithGeohashOptions) (int, error) {
@@ -321,6 +402,10 @@ func WriteLocationsWithGeohash(ctx context.Context, opts *WriteLocationsWithGeoh
 
 	cb_func := func(ctx context.Context, loc *location.Location) error {
 
+		if opts.Filter != nil && !opts.Filter(loc) {
+			return nil
+		}
+
 		enc_loc, err := json.Marshal(loc)
 
 		if err != nil {
@@ -347,3 +432,54 @@ func WriteLocationsWithGeohash(ctx context.Context, opts *WriteLocationsWithGeoh
 	opts.Logger.Debug("Got locations with geohash", "label", opts.Label, "count", count, "time", time.Since(t1))
 	return count, nil
 }
+
+// targetRegions returns the unique set of Who's On First region IDs for the locations in 'db' matching 'geohash'.
+// Locations whose region is not known are assigned a region ID of 0.
+func targetRegions(ctx context.Context, db location.Database, geohash string) ([]int64, error) {
+
+	seen := make(map[int64]bool)
+	regions := make([]int64, 0)
+
+	cb := func(ctx context.Context, loc *location.Location) error {
+
+		region_id := loc.RegionId()
+
+		if !seen[region_id] {
+			seen[region_id] = true
+			regions = append(regions, region_id)
+		}
+
+		return nil
+	}
+
+	err := db.GetWithGeohash(ctx, geohash, cb)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get target locations with geohash %s, %w", geohash, err)
+	}
+
+	return regions, nil
+}
+
+// sourceRegionFilter returns a `LocationFilter` which includes source locations in the region matching 'region_id'
+// or whose region is not known. If 'region_id' is 0 then all source locations are included.
+func sourceRegionFilter(region_id int64) LocationFilter {
+
+	return func(loc *location.Location) bool {
+
+		if region_id == 0 {
+			return true
+		}
+
+		loc_region_id := loc.RegionId()
+		return loc_region_id == 0 || loc_region_id == region_id
+	}
+}
+
+// targetRegionFilter returns a `LocationFilter` which includes target locations in the region matching 'region_id'.
+func targetRegionFilter(region_id int64) LocationFilter {
+
+	return func(loc *location.Location) bool {
+		return loc.RegionId() == region_id
+	}
+}
@@ -0,0 +1,122 @@
+package compare
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/sfomuseum/go-csvdict"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+func TestCompareLocationDatabases(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "compare")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	pt := orb.Point{-73.60033, 45.524115}
+
+	source_locs := []*location.Location{
+		&location.Location{ID: "ovtr:id=a", Name: "Cafe Olimpico", Centroid: &pt, Phones: []string{"+15144950746"}, Provenance: &location.Provenance{Source: "overture"}},
+		&location.Location{ID: "ovtr:id=b", Name: "Dieu du Ciel", Centroid: &pt},
+	}
+
+	target_locs := []*location.Location{
+		&location.Location{ID: "wof:id=1", Name: "Olimpico", Centroid: &pt, Phones: []string{"+15144950746"}},
+		&location.Location{ID: "wof:id=2", Name: "Dieu du Ciel!", Centroid: &pt, Concordances: map[string]string{"ovtr:id": "b"}},
+		&location.Location{ID: "wof:id=3", Name: "Pizzeria Magpie", Centroid: &pt},
+	}
+
+	source_uri := fmt.Sprintf("memory://?snapshot=%s", filepath.Join(tmp_dir, "source.jsonl"))
+	target_uri := fmt.Sprintf("memory://?snapshot=%s", filepath.Join(tmp_dir, "target.jsonl"))
+
+	for uri, locs := range map[string][]*location.Location{source_uri: source_locs, target_uri: target_locs} {
+
+		db, err := location.NewDatabase(ctx, uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create database, %v", err)
+		}
+
+		err = db.(location.BatchDatabase).AddLocations(ctx, locs)
+
+		if err != nil {
+			t.Fatalf("Failed to add locations, %v", err)
+		}
+
+		err = db.Close(ctx)
+
+		if err != nil {
+			t.Fatalf("Failed to close database, %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	opts := &CompareLocationDatabasesOptions{
+		SourceLocationDatabaseURI: source_uri,
+		TargetLocationDatabaseURI: target_uri,
+		VectorDatabaseURI:         "null://",
+		Threshold:                 1.0,
+		Workers:                   1,
+		Writer:                    &buf,
+	}
+
+	err = CompareLocationDatabases(ctx, opts)
+
+	if err != nil {
+		t.Fatalf("Failed to compare location databases, %v", err)
+	}
+
+	r, err := csvdict.NewReader(&buf)
+
+	if err != nil {
+		t.Fatalf("Failed to create CSV reader, %v", err)
+	}
+
+	matches := make(map[string]string)
+	sources := make(map[string]string)
+
+	for {
+
+		row, err := r.Read()
+
+		if err != nil {
+			break
+		}
+
+		matches[row["target_id"]] = fmt.Sprintf("%s %s", row["source_id"], row["match"])
+		sources[row["target_id"]] = row["source_source"]
+	}
+
+	expected := map[string]string{
+		"wof:id=1": "ovtr:id=a phone",
+		"wof:id=2": "ovtr:id=b concordance",
+	}
+
+	if len(matches) != len(expected) {
+		t.Fatalf("Unexpected matches: %v", matches)
+	}
+
+	for k, v := range expected {
+
+		if matches[k] != v {
+			t.Fatalf("Unexpected match for %s: '%s' (expected '%s')", k, matches[k], v)
+		}
+	}
+
+	if sources["wof:id=1"] != "overture" {
+		t.Fatalf("Unexpected source_source column for wof:id=1: '%s'", sources["wof:id=1"])
+	}
+}
@@ -0,0 +1,145 @@
+package compare
+
+import (
+	"fmt"
+
+	"github.com/whosonfirst/go-dedupe/location"
+	"github.com/whosonfirst/go-dedupe/names"
+)
+
+// The kinds of matches emitted by the compare methods.
+const (
+	// MATCH_VECTOR indicates a match derived by comparing vector embeddings.
+	MATCH_VECTOR string = "vector"
+	// MATCH_CONCORDANCE indicates an exact match derived from a concordance in one of the locations.
+	MATCH_CONCORDANCE string = "concordance"
+	// MATCH_PHONE indicates an exact match derived from a shared phone number.
+	MATCH_PHONE string = "phone"
+	// MATCH_DOMAIN indicates an exact match derived from a shared website domain.
+	MATCH_DOMAIN string = "domain"
+	// MATCH_NAME indicates an exact match derived from a shared (normalized) name and address.
+	MATCH_NAME string = "name"
+)
+
+// exactMatcher matches locations against a fixed set of source locations using "strong" identifiers (concordances,
+// phone numbers, website domains and normalized name and address keys) rather than vector embeddings.
+type exactMatcher struct {
+	sources          []*location.Location
+	has_concordances bool
+	phones           map[string][]string
+	domains          map[string][]string
+	names            map[string][]string
+}
+
+// newExactMatcher returns a new `exactMatcher` instance for 'sources'.
+func newExactMatcher(sources []*location.Location) *exactMatcher {
+
+	phones := make(map[string][]string)
+	domains := make(map[string][]string)
+	names := make(map[string][]string)
+	has_concordances := false
+
+	for _, loc := range sources {
+
+		if len(loc.Concordances) > 0 {
+			has_concordances = true
+		}
+
+		for _, p := range loc.Phones {
+			phones[p] = append(phones[p], loc.ID)
+		}
+
+		for _, d := range loc.Domains {
+			domains[d] = append(domains[d], loc.ID)
+		}
+
+		k := nameKey(loc)
+
+		if k != "" {
+			names[k] = append(names[k], loc.ID)
+		}
+	}
+
+	m := &exactMatcher{
+		sources:          sources,
+		has_concordances: has_concordances,
+		phones:           phones,
+		domains:          domains,
+		names:            names,
+	}
+
+	return m
+}
+
+// Match returns the ID of the source location which shares a concordance, phone number, website domain or name and
+// address key (in that order of precedence) with 'loc' and the kind of match. Phone numbers, domains and name keys only yield a match if they are
+// unique among the source locations; for example a chain's website domain shared by several branches in the same
+// geohash is ignored. If there is no match then empty strings are returned.
+func (m *exactMatcher) Match(loc *location.Location) (string, string) {
+
+	concordance_matches := make([]string, 0)
+
+	for _, source := range m.sources {
+
+		if !m.has_concordances && len(loc.Concordances) == 0 {
+			break
+		}
+
+		if source.ID == loc.ID {
+			continue
+		}
+
+		if source.HasConcordance(loc.ID) || loc.HasConcordance(source.ID) {
+			concordance_matches = append(concordance_matches, source.ID)
+		}
+	}
+
+	if len(concordance_matches) == 1 {
+		return concordance_matches[0], MATCH_CONCORDANCE
+	}
+
+	for _, p := range loc.Phones {
+
+		ids := m.phones[p]
+
+		if len(ids) == 1 && ids[0] != loc.ID {
+			return ids[0], MATCH_PHONE
+		}
+	}
+
+	for _, d := range loc.Domains {
+
+		ids := m.domains[d]
+
+		if len(ids) == 1 && ids[0] != loc.ID {
+			return ids[0], MATCH_DOMAIN
+		}
+	}
+
+	k := nameKey(loc)
+
+	if k != "" {
+
+		ids := m.names[k]
+
+		if len(ids) == 1 && ids[0] != loc.ID {
+			return ids[0], MATCH_NAME
+		}
+	}
+
+	return "", ""
+}
+
+// nameKey returns a key for exact matching derived from the `names.Key` value of the location's name and
+// its normalized address. If either is empty then an empty string is returned.
+func nameKey(loc *location.Location) string {
+
+	name := names.Key(loc.Name)
+	addr := loc.Normalize().Address
+
+	if name == "" || addr == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s#%s", name, addr)
+}
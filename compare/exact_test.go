@@ -0,0 +1,38 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+func TestExactMatcherNames(t *testing.T) {
+
+	sources := []*location.Location{
+		{ID: "wof:id=1", Name: "T&T Pest Control, Inc.", Address: "165 10th Street, San Francisco CA"},
+		{ID: "wof:id=2", Name: "Starbucks", Address: "1 Market St, San Francisco CA"},
+		{ID: "wof:id=3", Name: "Starbucks", Address: "1 Market St, San Francisco CA"},
+	}
+
+	m := newExactMatcher(sources)
+
+	tests := map[*location.Location]string{
+		{ID: "ovtr:id=a", Name: "T and T Pest Control", Address: "165 10th St, San Francisco CA"}:    "wof:id=1",
+		{ID: "ovtr:id=b", Name: "T and T Pest Control", Address: "200 Mission St, San Francisco CA"}: "",
+		// Not unique among the source locations
+		{ID: "ovtr:id=c", Name: "Starbucks", Address: "1 Market St, San Francisco CA"}: "",
+	}
+
+	for loc, expected := range tests {
+
+		id, match := m.Match(loc)
+
+		if id != expected {
+			t.Fatalf("Expected '%s' for %s, got '%s' (%s)", expected, loc.String(), id, match)
+		}
+
+		if id != "" && match != MATCH_NAME {
+			t.Fatalf("Expected name match for %s, got %s", loc.String(), match)
+		}
+	}
+}
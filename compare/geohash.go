@@ -9,12 +9,13 @@ import (
 	"net/url"
 	// "os"
 	"strings"
-	// "sync"
-	// "sync/atomic"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aaronland/go-jsonl/walk"
 	"github.com/aaronland/gocloud-blob/bucket"
+	"github.com/whosonfirst/go-dedupe/category"
 	"github.com/whosonfirst/go-dedupe/location"
 	"github.com/whosonfirst/go-dedupe/vector"
 	// "github.com/whosonfirst/go-overture/geojsonl"
@@ -29,8 +30,13 @@ type CompareLocationsForGeohashOptions struct {
 	WriterPrefix      string
 	VectorDatabaseURI string
 	Geohash           string
-	Threshold         float64
-	RowChannel        chan (map[string]string)
+	// If non-zero, the Who's On First ID of the region whose locations are being compared. This is used to ensure
+	// vector database URIs are unique for each (geohash, region) block.
+	Region    int64
+	Threshold float64
+	// If true then candidate matches whose (normalized) categories are not compatible are rejected.
+	CheckCategories bool
+	RowChannel      chan (map[string]string)
 }
 
 func CompareLocationsForGeohash(ctx context.Context, opts *CompareLocationsForGeohashOptions) error {
@@ -68,24 +74,83 @@ func CompareLocationsForGeohash(ctx context.Context, opts *CompareLocationsForGe
 
 	defer target_bucket.Close()
 
-	// Create the vector database
+	// Read the source locations
 
-	db_uri, _ := url.QueryUnescape(opts.VectorDatabaseURI)
-	db_uri = strings.Replace(db_uri, "{geohash}", opts.Geohash, 1)
+	sources_list := make([]*location.Location, 0)
+	sources_mu := new(sync.RWMutex)
 
-	vector_db, err := vector.NewDatabase(ctx, db_uri)
+	// Source locations keyed by ID, used to compare categories of candidate matches
+	sources := new(sync.Map)
+
+	source_walk_cb := func(ctx context.Context, path string, rec *walk.WalkRecord) error {
+
+		var loc *location.Location
+
+		err := json.Unmarshal(rec.Body, &loc)
+
+		if err != nil {
+			return fmt.Errorf("Failed to unmarshal record, %w", err)
+		}
+
+		sources_mu.Lock()
+		sources_list = append(sources_list, loc)
+		sources_mu.Unlock()
+
+		sources.Store(loc.ID, loc)
+		return nil
+	}
+
+	source_r, err := source_bucket.NewReader(ctx, opts.SourceLocations, nil)
 
 	if err != nil {
-		return fmt.Errorf("Failed to create new database, %w", err)
+		return err
 	}
 
-	defer vector_db.Close(ctx)
+	defer source_r.Close()
 
-	// Populate the vector database
+	// logger.Info("Walk sources", "path", opts.SourceLocations)
+	err = walk_reader(ctx, source_r, source_walk_cb)
 
-	count_sources := 0
+	if err != nil {
+		return fmt.Errorf("Failed to walk source locations, %w", err)
+	}
 
-	source_walk_cb := func(ctx context.Context, path string, rec *walk.WalkRecord) error {
+	// isCompatible returns false if category checks are enabled and the categories of the source location
+	// matching 'source_id' and 'loc' are not compatible.
+
+	isCompatible := func(source_id string, loc *location.Location) bool {
+
+		if !opts.CheckCategories {
+			return true
+		}
+
+		v, exists := sources.Load(source_id)
+
+		if !exists {
+			return true
+		}
+
+		source_loc := v.(*location.Location)
+
+		if !category.Compatible(source_loc.Category, loc.Category) {
+			logger.Debug("Reject match with incompatible category", "source", source_id, "source category", source_loc.Category, "target", loc.ID, "target category", loc.Category)
+			return false
+		}
+
+		return true
+	}
+
+	// Exact matches (concordances, phone numbers and website domains) before any embeddings are derived
+
+	matcher := newExactMatcher(sources_list)
+
+	count_targets := int64(0)
+	count_exact := int64(0)
+
+	// Target locations matched exactly, which are skipped when comparing vector embeddings
+	matched := new(sync.Map)
+
+	exact_walk_cb := func(ctx context.Context, path string, rec *walk.WalkRecord) error {
 
 		var loc *location.Location
 
@@ -95,35 +160,94 @@ func CompareLocationsForGeohash(ctx context.Context, opts *CompareLocationsForGe
 			return fmt.Errorf("Failed to unmarshal record, %w", err)
 		}
 
-		logger.Debug("Add to vector database", "location", loc.String())
-		err = vector_db.Add(ctx, loc)
+		atomic.AddInt64(&count_targets, 1)
 
-		if err != nil {
-			return fmt.Errorf("Failed to index location %s in vector db, %w", loc.ID, err)
+		source_id, match := matcher.Match(loc)
+
+		if source_id == "" || !isCompatible(source_id, loc) {
+			return nil
+		}
+
+		v, _ := sources.Load(source_id)
+		source_loc := v.(*location.Location)
+
+		logger.Info("Exact match", "match", match, "query", loc.String(), "candidate", source_loc.String())
+
+		row := map[string]string{
+			"geohash":    opts.Geohash,
+			"source_id":  source_id,
+			"target_id":  loc.ID,
+			"source":     source_loc.String(),
+			"target":     loc.String(),
+			"similarity": "",
+			"match":      match,
 		}
 
-		count_sources += 1
+		appendProvenance(row, source_loc, loc)
+
+		opts.RowChannel <- row
+
+		matched.Store(loc.ID, true)
+		atomic.AddInt64(&count_exact, 1)
+
 		return nil
 	}
 
-	source_r, err := source_bucket.NewReader(ctx, opts.SourceLocations, nil)
+	exact_r, err := target_bucket.NewReader(ctx, opts.TargetLocations, nil)
 
 	if err != nil {
 		return err
 	}
 
-	defer source_r.Close()
+	defer exact_r.Close()
 
-	t1 := time.Now()
+	err = walk_reader(ctx, exact_r, exact_walk_cb)
 
-	// logger.Info("Walk sources", "path", opts.SourceLocations)
-	err = walk_reader(ctx, source_r, source_walk_cb)
+	if err != nil {
+		return fmt.Errorf("Failed to walk target locations for exact matches, %w", err)
+	}
+
+	logger.Info("Exact matches", "count", count_exact, "targets", count_targets)
+
+	if count_exact == count_targets {
+		logger.Debug("All target locations matched exactly, skipping vector comparisons")
+		return nil
+	}
+
+	// Create the vector database
+
+	db_uri, _ := url.QueryUnescape(opts.VectorDatabaseURI)
+	block := opts.Geohash
+
+	if opts.Region != 0 {
+		block = fmt.Sprintf("%s-%d", opts.Geohash, opts.Region)
+	}
+
+	db_uri = strings.Replace(db_uri, "{geohash}", block, 1)
+
+	vector_db, err := vector.NewDatabase(ctx, db_uri)
 
 	if err != nil {
-		return fmt.Errorf("Failed to walk source locations, %w", err)
+		return fmt.Errorf("Failed to create new database, %w", err)
 	}
 
-	logger.Info("Time to index sources in vector db", "count", count_sources, "time", time.Since(t1))
+	defer vector_db.Close(ctx)
+
+	// Populate the vector database
+
+	t1 := time.Now()
+
+	for _, loc := range sources_list {
+
+		logger.Debug("Add to vector database", "location", loc.String())
+		err = vector_db.Add(ctx, loc)
+
+		if err != nil {
+			return fmt.Errorf("Failed to index location %s in vector db, %w", loc.ID, err)
+		}
+	}
+
+	logger.Info("Time to index sources in vector db", "count", len(sources_list), "time", time.Since(t1))
 
 	target_walk_cb := func(ctx context.Context, path string, rec *walk.WalkRecord) error {
 
@@ -135,6 +259,13 @@ func CompareLocationsForGeohash(ctx context.Context, opts *CompareLocationsForGe
 			return fmt.Errorf("Failed to unmarshal record, %w", err)
 		}
 
+		_, is_matched := matched.Load(loc.ID)
+
+		if is_matched {
+			logger.Debug("Location already matched exactly, skipping", "location", loc.String())
+			return nil
+		}
+
 		geohash := opts.Geohash
 		threshold := opts.Threshold
 
@@ -170,6 +301,10 @@ func CompareLocationsForGeohash(ctx context.Context, opts *CompareLocationsForGe
 				continue
 			}
 
+			if !isCompatible(qr.ID, loc) {
+				continue
+			}
+
 			logger.Info("Match", "threshold", threshold, "similarity", qr.Similarity, "query", loc.String(), "candidate", qr.Content)
 
 			row := map[string]string{
@@ -179,8 +314,19 @@ func CompareLocationsForGeohash(ctx context.Context, opts *CompareLocationsForGe
 				"source":     qr.Content,
 				"target":     loc.String(),
 				"similarity": fmt.Sprintf("%02f", qr.Similarity),
+				"match":      MATCH_VECTOR,
+			}
+
+			var source_loc *location.Location
+
+			v, exists := sources.Load(qr.ID)
+
+			if exists {
+				source_loc = v.(*location.Location)
 			}
 
+			appendProvenance(row, source_loc, loc)
+
 			opts.RowChannel <- row
 			break
 		}
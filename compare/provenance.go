@@ -0,0 +1,41 @@
+package compare
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+// appendProvenance adds the provenance properties of 'source' and 'target' to 'row' as "source_{KEY}"
+// and "target_{KEY}" columns respectively. Columns for unknown properties are added with empty values
+// so that every row has the same set of columns.
+func appendProvenance(row map[string]string, source *location.Location, target *location.Location) {
+
+	for label, loc := range map[string]*location.Location{"source": source, "target": target} {
+
+		pr := &location.Provenance{}
+
+		if loc != nil && loc.Provenance != nil {
+			pr = loc.Provenance
+		}
+
+		lastmod := ""
+		confidence := ""
+
+		if pr.LastModified > 0 {
+			lastmod = strconv.FormatInt(pr.LastModified, 10)
+		}
+
+		if pr.Confidence > 0 {
+			confidence = strconv.FormatFloat(pr.Confidence, 'f', -1, 64)
+		}
+
+		row[fmt.Sprintf("%s_source", label)] = pr.Source
+		row[fmt.Sprintf("%s_dataset", label)] = pr.Dataset
+		row[fmt.Sprintf("%s_release", label)] = pr.Release
+		row[fmt.Sprintf("%s_lastmodified", label)] = lastmod
+		row[fmt.Sprintf("%s_confidence", label)] = confidence
+		row[fmt.Sprintf("%s_geometry_source", label)] = pr.GeometrySource
+	}
+}
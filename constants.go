@@ -4,3 +4,4 @@ const WHOSONFIRST_PREFIX string = "wof"
 const OVERTURE_PREFIX string = "ovtr"
 const ALLTHEPLACES_PREFIX string = "atp"
 const ILMS_PREFIX string = "ilms"
+const OPENSTREETMAP_PREFIX string = "osm"
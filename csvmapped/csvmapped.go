@@ -0,0 +1,64 @@
+// Package csvmapped provides a generic iterator and parser for CSV files whose columns are mapped to location
+// properties by configuration rather than code.
+package csvmapped
+
+import (
+	"net/url"
+
+	"github.com/whosonfirst/go-dedupe/mapping"
+)
+
+// Mapping defines which columns in a CSV file contain the properties used to derive a `location.Location`.
+// Column names are case-sensitive. In addition to the properties defined by `mapping.Mapping` it defines the
+// columns containing each row's coordinates which are used by the `CSVIterator` to derive a geometry.
+type Mapping struct {
+	mapping.Mapping
+	// The name of the column containing the latitude of each row. Default is "latitude".
+	Latitude string `json:"latitude,omitempty"`
+	// The name of the column containing the longitude of each row. Default is "longitude".
+	Longitude string `json:"longitude,omitempty"`
+}
+
+// NewMappingFromURI returns a new `Mapping` instance derived from the query parameters in 'u'. If there is
+// a `?mapping=` parameter the mapping is first read from the JSON file it points to and then any other parameters,
+// named after the mapping's JSON keys with hyphens instead of underscores, are applied on top of it. See
+// `mapping.Mapping.ApplyQuery` for details.
+func NewMappingFromURI(u *url.URL) (*Mapping, error) {
+
+	q := u.Query()
+
+	m := &Mapping{}
+
+	if q.Has("mapping") {
+
+		err := mapping.ReadMappingFile(q.Get("mapping"), m)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := m.ApplyQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if q.Has("latitude") {
+		m.Latitude = q.Get("latitude")
+	}
+
+	if q.Has("longitude") {
+		m.Longitude = q.Get("longitude")
+	}
+
+	if m.Latitude == "" {
+		m.Latitude = "latitude"
+	}
+
+	if m.Longitude == "" {
+		m.Longitude = "longitude"
+	}
+
+	return m, nil
+}
@@ -0,0 +1,132 @@
+package csvmapped
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/whosonfirst/go-dedupe/iterator"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+func TestCSVMapped(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "csvmapped")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	csv_path := filepath.Join(tmp_dir, "venues.csv")
+	mapping_path := filepath.Join(tmp_dir, "mapping.json")
+
+	csv_body := "\ufeffVenue ID;Venue.Name;Address;City;Prov;Lat;Lng;Tel;Type\n" +
+		"1;Cafe Olimpico;124 Rue Saint-Viateur O;Montréal;QC;45.524050;-73.600050;514 495 0746;cafe\n" +
+		"2;No Coordinates;1 Main St;Montréal;QC;;;;\n" +
+		"3;No Address;;;;45.5;-73.6;;\n"
+
+	mapping_body := `{"prefix":"acme","id":"Venue ID","name":"Venue.Name","street":"Address","locality":"City","region":"Prov","default_country":"CA","latitude":"Lat","longitude":"Lng","phone":"Tel","category":"Type"}`
+
+	for path, body := range map[string]string{
+		csv_path:     csv_body,
+		mapping_path: mapping_body,
+	} {
+
+		err := os.WriteFile(path, []byte(body), 0644)
+
+		if err != nil {
+			t.Fatalf("Failed to write %s, %v", path, err)
+		}
+	}
+
+	q := url.Values{}
+	q.Set("mapping", mapping_path)
+	q.Set("delimiter", ";")
+
+	iter, err := iterator.NewIterator(ctx, fmt.Sprintf("csv://?%s", q.Encode()))
+
+	if err != nil {
+		t.Fatalf("Failed to create iterator, %v", err)
+	}
+
+	defer iter.Close(ctx)
+
+	q = url.Values{}
+	q.Set("mapping", mapping_path)
+	q.Set("source", "acme-venues")
+	q.Set("custom", "venue_type:Type")
+
+	prsr, err := location.NewParser(ctx, fmt.Sprintf("csvmapped://?%s", q.Encode()))
+
+	if err != nil {
+		t.Fatalf("Failed to create parser, %v", err)
+	}
+
+	locs := make([]*location.Location, 0)
+	mu := new(sync.Mutex)
+
+	iter_cb := func(ctx context.Context, body []byte) error {
+
+		loc, err := prsr.Parse(ctx, body)
+
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		locs = append(locs, loc)
+		mu.Unlock()
+
+		return nil
+	}
+
+	err = iter.IterateWithCallback(ctx, iter_cb, csv_path)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate, %v", err)
+	}
+
+	if len(locs) != 1 {
+		t.Fatalf("Expected 1 location, got %d", len(locs))
+	}
+
+	loc := locs[0]
+
+	if loc.ID != "acme:id=1" || loc.Name != "Cafe Olimpico" || loc.Address != "124 Rue Saint-Viateur O Montréal QC" {
+		t.Fatalf("Unexpected location: %s", loc.String())
+	}
+
+	if loc.AddressComponents.HouseNumber != "124" || loc.AddressComponents.Country != "CA" {
+		t.Fatalf("Unexpected address components: %v", loc.AddressComponents)
+	}
+
+	if loc.Centroid.Lon() != -73.600050 || loc.Centroid.Lat() != 45.524050 {
+		t.Fatalf("Unexpected centroid: %v", loc.Centroid)
+	}
+
+	if len(loc.Phones) != 1 || loc.Phones[0] != "+15144950746" {
+		t.Fatalf("Unexpected phones: %v", loc.Phones)
+	}
+
+	if loc.Provenance.Source != "acme-venues" {
+		t.Fatalf("Unexpected provenance source: %s", loc.Provenance.Source)
+	}
+
+	if loc.Custom["venue_type"] != "cafe" {
+		t.Fatalf("Unexpected custom properties: %v", loc.Custom)
+	}
+
+	_, err = location.NewParser(ctx, "csvmapped://?id=ID&name=NAME")
+
+	if err == nil {
+		t.Fatalf("Expected parser without a prefix to fail")
+	}
+}
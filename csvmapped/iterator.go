@@ -0,0 +1,257 @@
+package csvmapped
+
+// > go run cmd/index-locations/main.go -verbose -location-database-uri null:// -location-parser-uri 'csvmapped://?mapping=/usr/local/data/acme/mapping.json' -iterator-uri 'csv://?mapping=/usr/local/data/acme/mapping.json' /usr/local/data/acme/*.csv
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/whosonfirst/go-dedupe/iterator"
+)
+
+// CSVIterator implements the `iterator.Iterator` interface for CSV files with a header row and latitude and longitude columns.
+type CSVIterator struct {
+	iterator.Iterator
+	mapping     *Mapping
+	delimiter   rune
+	max_workers int
+}
+
+func init() {
+	ctx := context.Background()
+	err := iterator.RegisterIterator(ctx, "csv", NewCSVIterator)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewCSVIterator returns a new `CSVIterator` instance configured by 'uri' which is expected to take the form of:
+//
+//	csv://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?mapping=` The path to a JSON-encoded `Mapping` file. Only its "latitude" and "longitude" properties are used by the iterator.
+// * `?latitude=` The name of the column containing latitudes. Default is "latitude".
+// * `?longitude=` The name of the column containing longitudes. Default is "longitude".
+// * `?delimiter=` The field delimiter; a single character or "tab". Default is ",".
+// * `?max-workers=` The maximum number of callbacks to invoke concurrently. Default is 20.
+func NewCSVIterator(ctx context.Context, uri string) (iterator.Iterator, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	m, err := NewMappingFromURI(u)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive mapping, %w", err)
+	}
+
+	max_workers := 20
+
+	if q.Has("max-workers") {
+
+		v, err := strconv.Atoi(q.Get("max-workers"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?max-workers= parameter, %w", err)
+		}
+
+		max_workers = v
+	}
+
+	delimiter := ','
+
+	if q.Has("delimiter") {
+
+		v := q.Get("delimiter")
+
+		if v == "tab" {
+			v = "\t"
+		}
+
+		if utf8.RuneCountInString(v) != 1 {
+			return nil, fmt.Errorf("Invalid ?delimiter= parameter, must be a single character")
+		}
+
+		delimiter, _ = utf8.DecodeRuneInString(v)
+	}
+
+	iter := &CSVIterator{
+		mapping:     m,
+		delimiter:   delimiter,
+		max_workers: max_workers,
+	}
+
+	return iter, nil
+}
+
+// IterateWithCallback emits each row, with valid latitude and longitude values, in one or more CSV files as a GeoJSON
+// Point feature whose properties are the row's columns.
+func (iter *CSVIterator) IterateWithCallback(ctx context.Context, cb iterator.IteratorCallback, uris ...string) error {
+
+	throttle := make(chan bool, iter.max_workers)
+
+	for i := 0; i < iter.max_workers; i++ {
+		throttle <- true
+	}
+
+	wg := new(sync.WaitGroup)
+
+	defer wg.Wait()
+
+	for _, path := range uris {
+
+		logger := slog.Default()
+		logger = logger.With("path", path)
+
+		row_cb := func(ctx context.Context, row_number int, body []byte) {
+
+			<-throttle
+
+			wg.Add(1)
+
+			go func(row_number int, body []byte) {
+
+				defer func() {
+					wg.Done()
+					throttle <- true
+				}()
+
+				err := cb(ctx, body)
+
+				if err != nil {
+					logger.Warn("Callback failed for row", "row", row_number, "error", err)
+				}
+
+			}(row_number, body)
+		}
+
+		err := iter.iteratePath(ctx, path, row_cb)
+
+		if err != nil {
+			return fmt.Errorf("Failed to iterate %s, %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (iter *CSVIterator) Close(ctx context.Context) error {
+	return nil
+}
+
+func (iter *CSVIterator) iteratePath(ctx context.Context, path string, cb func(context.Context, int, []byte)) error {
+
+	r, err := os.Open(path)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open %s for reading, %w", path, err)
+	}
+
+	defer r.Close()
+
+	csv_r := csv.NewReader(r)
+	csv_r.Comma = iter.delimiter
+	csv_r.FieldsPerRecord = -1
+
+	header, err := csv_r.Read()
+
+	if err != nil {
+		return fmt.Errorf("Failed to read header, %w", err)
+	}
+
+	// Remove any byte order mark from the first column name
+
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	row_number := 1
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		values, err := csv_r.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return err
+		}
+
+		row_number += 1
+
+		logger := slog.Default()
+		logger = logger.With("path", path)
+		logger = logger.With("row", row_number)
+
+		props := make(map[string]interface{})
+
+		for i, k := range header {
+
+			if i < len(values) {
+				props[k] = values[i]
+			}
+		}
+
+		str_lat, _ := props[iter.mapping.Latitude].(string)
+		str_lon, _ := props[iter.mapping.Longitude].(string)
+
+		if strings.TrimSpace(str_lat) == "" || strings.TrimSpace(str_lon) == "" {
+			logger.Debug("Row is missing latitude or longitude, skipping")
+			continue
+		}
+
+		lat, err := strconv.ParseFloat(strings.TrimSpace(str_lat), 64)
+
+		if err != nil {
+			logger.Warn("Invalid latitude for row, skipping", "latitude", str_lat, "error", err)
+			continue
+		}
+
+		lon, err := strconv.ParseFloat(strings.TrimSpace(str_lon), 64)
+
+		if err != nil {
+			logger.Warn("Invalid longitude for row, skipping", "longitude", str_lon, "error", err)
+			continue
+		}
+
+		f := geojson.NewFeature(orb.Point([2]float64{lon, lat}))
+		f.Properties = props
+
+		enc_f, err := f.MarshalJSON()
+
+		if err != nil {
+			logger.Warn("Failed to marshal feature for row, skipping", "error", err)
+			continue
+		}
+
+		cb(ctx, row_number, enc_f)
+	}
+
+	return nil
+}
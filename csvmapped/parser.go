@@ -0,0 +1,112 @@
+package csvmapped
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/location"
+	"github.com/whosonfirst/go-dedupe/mapping"
+)
+
+// CSVMappedParser implements the `location.Parser` interface for GeoJSON features produced by the `CSVIterator`
+// whose properties are mapped to location properties using a `Mapping` instance.
+type CSVMappedParser struct {
+	location.Parser
+	mapping *Mapping
+	release string
+}
+
+func init() {
+	ctx := context.Background()
+	err := location.RegisterParser(ctx, "csvmapped", NewCSVMappedParser)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewCSVMappedParser returns a new `CSVMappedParser` instance configured by 'uri' which is expected to take the form of:
+//
+//	csvmapped://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be a `?mapping=` parameter pointing to a JSON-encoded `Mapping` file and/or individual
+// mapping parameters (for example `?id=` or `?house-number=`) as described in `NewMappingFromURI`. The "prefix",
+// "id" and "name" properties are required. The prefix is registered as a valid ID prefix.
+func NewCSVMappedParser(ctx context.Context, uri string) (location.Parser, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	m, err := NewMappingFromURI(u)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive mapping, %w", err)
+	}
+
+	err = m.Validate()
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid mapping, %w", err)
+	}
+
+	if m.ID == "" {
+		return nil, fmt.Errorf("Missing id column")
+	}
+
+	err = dedupe.RegisterIDPrefix(m.Prefix, "")
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to register prefix, %w", err)
+	}
+
+	p := &CSVMappedParser{
+		mapping: m,
+		release: q.Get("release"),
+	}
+
+	return p, nil
+}
+
+func (p *CSVMappedParser) Parse(ctx context.Context, body []byte) (*location.Location, error) {
+
+	// Column names may contain characters (like ".") which have special meaning in gjson paths so
+	// read all the properties up front rather than querying them individually.
+
+	props := make(map[string]string)
+
+	gjson.GetBytes(body, "properties").ForEach(func(k gjson.Result, v gjson.Result) bool {
+		props[k.String()] = strings.TrimSpace(v.String())
+		return true
+	})
+
+	// Each column contains a single value so, unlike the jsonpath parser, phone and website columns
+	// yield (at most) one phone number or website.
+
+	column := func(k string) []string {
+
+		v := props[k]
+
+		if k == "" || v == "" {
+			return nil
+		}
+
+		return []string{v}
+	}
+
+	geom, err := mapping.FeatureGeometry(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mapping.NewLocation(&p.mapping.Mapping, column, geom, p.release)
+}
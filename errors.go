@@ -54,3 +54,25 @@ func (e *NotImplementedError) Error() string {
 func (e *NotImplementedError) String() string {
 	return e.Error()
 }
+
+type NotFoundError struct{}
+
+func NotFound() *NotFoundError {
+
+	e := &NotFoundError{}
+
+	return e
+}
+
+func IsNotFoundError(e error) bool {
+	var not_found *NotFoundError
+	return errors.As(e, &not_found)
+}
+
+func (e *NotFoundError) Error() string {
+	return "Not found"
+}
+
+func (e *NotFoundError) String() string {
+	return e.Error()
+}
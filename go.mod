@@ -12,6 +12,7 @@ require (
 	github.com/asg017/sqlite-vec-go-bindings v0.1.1
 	github.com/blevesearch/bleve/v2 v2.4.2
 	github.com/bwmarrin/snowflake v0.3.0
+	github.com/dsnet/compress v0.0.1
 	github.com/json-iterator/go v1.1.12
 	github.com/marcboeker/go-duckdb v1.7.1
 	github.com/mattn/go-sqlite3 v1.14.22
@@ -37,6 +38,8 @@ require (
 	github.com/whosonfirst/go-whosonfirst-writer/v3 v3.1.4
 	github.com/whosonfirst/go-writer/v3 v3.1.1
 	gocloud.dev v0.39.0
+	golang.org/x/text v0.17.0
+	google.golang.org/protobuf v1.34.2
 )
 
 require (
@@ -130,11 +133,9 @@ require (
 	golang.org/x/net v0.28.0 // indirect
 	golang.org/x/sync v0.8.0 // indirect
 	golang.org/x/sys v0.24.0 // indirect
-	golang.org/x/text v0.17.0 // indirect
 	golang.org/x/tools v0.22.0 // indirect
 	golang.org/x/xerrors v0.0.0-20240716161551-93cc26a95ae9 // indirect
 	google.golang.org/api v0.191.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240812133136-8ffd90a71988 // indirect
 	google.golang.org/grpc v1.65.0 // indirect
-	google.golang.org/protobuf v1.34.2 // indirect
 )
@@ -0,0 +1,144 @@
+// Package identifiers provides methods for normalizing "strong" identifiers, like phone numbers and website
+// domains, which can be used to match records deterministically before (or instead of) comparing vector
+// embeddings.
+package identifiers
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MIN_PHONE_DIGITS is the minimum number of digits (including the country calling code) for a valid E.164 phone number.
+const MIN_PHONE_DIGITS int = 8
+
+// MAX_PHONE_DIGITS is the maximum number of digits (including the country calling code) for a valid E.164 phone number.
+const MAX_PHONE_DIGITS int = 15
+
+// re_phone_extension matches an extension, for example "ext. 12", "x12" or "#12", at the end of a phone number.
+var re_phone_extension = regexp.MustCompile(`(?i)\s*(?:ext\.?|extension|x|#)\s*\d+\s*$`)
+
+// keypad maps the (lower-case) letters on a telephone keypad to their digits.
+const keypad string = "22233344455566677778889999"
+
+// NormalizePhone returns 'raw' as an E.164 formatted phone number (for example "+15551234567"). If 'raw' does not
+// contain an international prefix ("+" or "00") then 'country' (an ISO 3166-1 alpha-2 code) is used to determine
+// the country calling code. If 'raw' can not be normalized (for example because the country calling code can not
+// be determined or the number is too short or too long) then an empty string is returned. Extensions and leading
+// labels (like "tel:") are removed and the letters in vanity numbers are replaced by their keypad digits.
+func NormalizePhone(raw string, country string) string {
+
+	raw = strings.TrimSpace(strings.ToLower(raw))
+
+	// Remove extensions (only when followed by digits) and any leading labels like "tel:" or "fax"
+
+	raw = re_phone_extension.ReplaceAllString(raw, "")
+	raw = strings.TrimLeftFunc(raw, func(r rune) bool {
+		return r != '+' && !unicode.IsDigit(r)
+	})
+
+	international := strings.HasPrefix(raw, "+")
+
+	// Numbers written in international format sometimes include the national trunk prefix in parentheses,
+	// for example "+44 (0)20 7946 0018", which is not dialed from abroad
+
+	if international || strings.HasPrefix(raw, "00") {
+		raw = strings.Replace(raw, "(0)", "", 1)
+	}
+
+	var buf strings.Builder
+
+	for _, r := range raw {
+
+		switch {
+		case r >= '0' && r <= '9':
+			buf.WriteRune(r)
+		case r >= 'a' && r <= 'z':
+			// Vanity numbers, for example "1-800-FLOWERS"
+			buf.WriteByte(keypad[r-'a'])
+		default:
+			// pass
+		}
+	}
+
+	digits := buf.String()
+
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+
+	if !international {
+
+		cc, exists := calling_codes[strings.ToUpper(country)]
+
+		if !exists {
+			return ""
+		}
+
+		switch cc {
+		case "1":
+
+			// North American Numbering Plan
+
+			if len(digits) == 11 && strings.HasPrefix(digits, "1") {
+				digits = digits[1:]
+			}
+
+			if len(digits) != 10 {
+				return ""
+			}
+
+		default:
+
+			if !keeps_trunk_prefix[strings.ToUpper(country)] {
+				digits = strings.TrimLeft(digits, "0")
+			}
+		}
+
+		digits = cc + digits
+	}
+
+	if len(digits) < MIN_PHONE_DIGITS || len(digits) > MAX_PHONE_DIGITS {
+		return ""
+	}
+
+	return "+" + digits
+}
+
+// WebsiteDomain returns the lower-cased domain (host) name for the website URL 'raw' with any leading "www."
+// prefix removed. If 'raw' can not be parsed or its domain is a shared platform (for example facebook.com or
+// instagram.com) which can not be used to identify a single venue then an empty string is returned.
+func WebsiteDomain(raw string) string {
+
+	raw = strings.TrimSpace(raw)
+
+	if raw == "" {
+		return ""
+	}
+
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+
+	u, err := url.Parse(raw)
+
+	if err != nil {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	host = strings.TrimSuffix(host, ".")
+	host = strings.TrimPrefix(host, "www.")
+
+	if !strings.Contains(host, ".") {
+		return ""
+	}
+
+	if isPlatformDomain(host) {
+		return ""
+	}
+
+	return host
+}
@@ -0,0 +1,65 @@
+package identifiers
+
+import (
+	"testing"
+)
+
+func TestNormalizePhone(t *testing.T) {
+
+	tests := [][3]string{
+		{"(718) 555-1234", "US", "+17185551234"},
+		{"1-718-555-1234", "US", "+17185551234"},
+		{"+1 718 555 1234", "", "+17185551234"},
+		{"718.555.1234 ext 12", "US", "+17185551234"},
+		{"514-555-1234", "CA", "+15145551234"},
+		{"020 7946 0018", "GB", "+442079460018"},
+		{"0044 20 7946 0018", "", "+442079460018"},
+		{"06 1234 5678", "IT", "+390612345678"},
+		{"555-1234", "US", ""},
+		{"(718) 555-1234", "", ""},
+		// Extensions
+		{"718-555-1234 x22", "US", "+17185551234"},
+		{"718-555-1234 #5", "US", "+17185551234"},
+		{"718.555.1234 Ext. 3", "US", "+17185551234"},
+		// Labels and vanity numbers containing the letter "x"
+		{"Fax: 514-555-1234", "CA", "+15145551234"},
+		{"1-800-BOXES-99", "US", "+18002693799"},
+		{"1-800-FLOWERS", "US", "+18003569377"},
+		// National trunk prefixes in international numbers
+		{"+44 (0)20 7946 0018", "", "+442079460018"},
+		{"0044 (0)20 7946 0018", "", "+442079460018"},
+		{"(0)20 7946 0018", "GB", "+442079460018"},
+	}
+
+	for _, test := range tests {
+
+		phone := NormalizePhone(test[0], test[1])
+
+		if phone != test[2] {
+			t.Fatalf("Unexpected phone number for '%s' (%s). Expected '%s' but got '%s'", test[0], test[1], test[2], phone)
+		}
+	}
+}
+
+func TestWebsiteDomain(t *testing.T) {
+
+	tests := map[string]string{
+		"https://www.example.com/locations/123": "example.com",
+		"http://Example.com":                    "example.com",
+		"example.com/about":                     "example.com",
+		"https://shop.example.com:8080/":        "shop.example.com",
+		"https://www.facebook.com/example":      "",
+		"https://m.facebook.com/example":        "",
+		"not a website":                         "",
+		"":                                      "",
+	}
+
+	for raw, expected := range tests {
+
+		domain := WebsiteDomain(raw)
+
+		if domain != expected {
+			t.Fatalf("Unexpected domain for '%s'. Expected '%s' but got '%s'", raw, expected, domain)
+		}
+	}
+}
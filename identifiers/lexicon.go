@@ -0,0 +1,85 @@
+package identifiers
+
+import (
+	"strings"
+)
+
+// The lookup tables in this file are not meant to be exhaustive. They cover the countries and websites
+// most commonly encountered in the data sources supported by this package.
+
+// calling_codes maps ISO 3166-1 alpha-2 country codes to their country calling codes.
+var calling_codes = map[string]string{
+	"AR": "54",
+	"AT": "43",
+	"AU": "61",
+	"BE": "32",
+	"BR": "55",
+	"CA": "1",
+	"CH": "41",
+	"CL": "56",
+	"CN": "86",
+	"CO": "57",
+	"CZ": "420",
+	"DE": "49",
+	"DK": "45",
+	"ES": "34",
+	"FI": "358",
+	"FR": "33",
+	"GB": "44",
+	"GR": "30",
+	"HK": "852",
+	"IE": "353",
+	"IL": "972",
+	"IN": "91",
+	"IT": "39",
+	"JP": "81",
+	"KR": "82",
+	"MX": "52",
+	"NL": "31",
+	"NO": "47",
+	"NZ": "64",
+	"PL": "48",
+	"PR": "1",
+	"PT": "351",
+	"SE": "46",
+	"SG": "65",
+	"TW": "886",
+	"US": "1",
+	"ZA": "27",
+}
+
+// keeps_trunk_prefix is the list of ISO 3166-1 alpha-2 country codes whose phone numbers retain their
+// leading "0" when written in international format.
+var keeps_trunk_prefix = map[string]bool{
+	"IT": true,
+}
+
+// platform_domains is the list of domains which host pages for many unrelated venues and so can not be used as identifiers.
+var platform_domains = []string{
+	"business.site",
+	"facebook.com",
+	"foursquare.com",
+	"google.com",
+	"instagram.com",
+	"linktr.ee",
+	"square.site",
+	"squarespace.com",
+	"tiktok.com",
+	"tripadvisor.com",
+	"twitter.com",
+	"wixsite.com",
+	"x.com",
+	"yelp.com",
+}
+
+func isPlatformDomain(host string) bool {
+
+	for _, d := range platform_domains {
+
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+
+	return false
+}
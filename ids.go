@@ -2,8 +2,249 @@ package dedupe
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 )
 
+// DEFAULT_ID_PREDICATE is the default predicate for IDs derived from a provider's prefix and unique identifier.
+const DEFAULT_ID_PREDICATE string = "id"
+
+var re_id_token = regexp.MustCompile(`^[a-z0-9_\-]+$`)
+
+// ID defines a namespaced identifier for a location taking the form of "{PREFIX}:{PREDICATE}={VALUE}",
+// for example "wof:id=1234" or "ovtr:id=08f2a100d8ac2b0d03a4d1aab1a4d8ba".
+type ID struct {
+	// The (registered) prefix of the data provider that the ID belongs to
+	Prefix string
+	// The type of identifier; usually "id"
+	Predicate string
+	// The unique identifier in the data provider's namespace
+	Value string
+}
+
+// id_namespaces maps registered ID prefixes to their corresponding concordance namespace.
+var id_namespaces = map[string]string{
+	WHOSONFIRST_PREFIX:   WHOSONFIRST_PREFIX,
+	OVERTURE_PREFIX:      OVERTURE_PREFIX,
+	ALLTHEPLACES_PREFIX:  ALLTHEPLACES_PREFIX,
+	ILMS_PREFIX:          ILMS_PREFIX,
+	OPENSTREETMAP_PREFIX: OPENSTREETMAP_PREFIX,
+}
+
+var id_namespaces_mu = new(sync.RWMutex)
+
+// RegisterIDPrefix registers 'prefix' as a valid ID prefix whose concordance namespace (as used by the
+// "wof:concordances" property in Who's On First records) is 'namespace'. If 'namespace' is empty then
+// 'prefix' is used. It is an error to register the same prefix with different namespaces.
+func RegisterIDPrefix(prefix string, namespace string) error {
+
+	if !re_id_token.MatchString(prefix) {
+		return fmt.Errorf("Invalid prefix '%s'", prefix)
+	}
+
+	if namespace == "" {
+		namespace = prefix
+	}
+
+	id_namespaces_mu.Lock()
+	defer id_namespaces_mu.Unlock()
+
+	existing, exists := id_namespaces[prefix]
+
+	if exists {
+
+		if existing != namespace {
+			return fmt.Errorf("Prefix '%s' is already registered with namespace '%s'", prefix, existing)
+		}
+
+		return nil
+	}
+
+	id_namespaces[prefix] = namespace
+	return nil
+}
+
+// IDPrefixes returns the sorted list of registered ID prefixes.
+func IDPrefixes() []string {
+
+	id_namespaces_mu.RLock()
+	defer id_namespaces_mu.RUnlock()
+
+	prefixes := make([]string, 0)
+
+	for p, _ := range id_namespaces {
+		prefixes = append(prefixes, p)
+	}
+
+	sort.Strings(prefixes)
+	return prefixes
+}
+
+// IsRegisteredIDPrefix returns a boolean value indicating whether 'prefix' has been registered.
+func IsRegisteredIDPrefix(prefix string) bool {
+
+	id_namespaces_mu.RLock()
+	defer id_namespaces_mu.RUnlock()
+
+	_, exists := id_namespaces[prefix]
+	return exists
+}
+
+// NewID returns a new `ID` instance for 'value' in the namespace of 'prefix' using the default ("id") predicate.
+func NewID(prefix string, value string) *ID {
+
+	id := &ID{
+		Prefix:    prefix,
+		Predicate: DEFAULT_ID_PREDICATE,
+		Value:     value,
+	}
+
+	return id
+}
+
+// ParseID parses 'str', which is expected to take the form of "{PREFIX}:{PREDICATE}={VALUE}", and returns a new `ID`
+// instance. An error is returned if 'str' is not a well-formed ID or if its prefix has not been registered.
+func ParseID(str string) (*ID, error) {
+
+	id, err := ParseUnregisteredID(str)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsRegisteredIDPrefix(id.Prefix) {
+		return nil, fmt.Errorf("Invalid ID '%s', unregistered prefix '%s'", str, id.Prefix)
+	}
+
+	return id, nil
+}
+
+// ParseUnregisteredID parses 'str', which is expected to take the form of "{PREFIX}:{PREDICATE}={VALUE}", and returns
+// a new `ID` instance without requiring that its prefix has been registered. This is useful for tools which read IDs
+// created by another process, for example one whose parser registered a custom prefix. An error is returned if 'str'
+// is not a well-formed ID.
+func ParseUnregisteredID(str string) (*ID, error) {
+
+	ns, value, ok := strings.Cut(str, "=")
+
+	if !ok {
+		return nil, fmt.Errorf("Invalid ID '%s', missing '=' separator", str)
+	}
+
+	prefix, predicate, ok := strings.Cut(ns, ":")
+
+	if !ok {
+		return nil, fmt.Errorf("Invalid ID '%s', missing ':' separator", str)
+	}
+
+	id := &ID{
+		Prefix:    prefix,
+		Predicate: predicate,
+		Value:     value,
+	}
+
+	err := id.validateSyntax()
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ID '%s', %w", str, err)
+	}
+
+	return id, nil
+}
+
+// Validate returns an error if the ID's prefix has not been registered or if any of its components are empty or invalid.
+func (id *ID) Validate() error {
+
+	err := id.validateSyntax()
+
+	if err != nil {
+		return err
+	}
+
+	if !IsRegisteredIDPrefix(id.Prefix) {
+		return fmt.Errorf("Unregistered prefix '%s'", id.Prefix)
+	}
+
+	return nil
+}
+
+// validateSyntax returns an error if any of the ID's components are empty or invalid. It does not check whether
+// the ID's prefix has been registered.
+func (id *ID) validateSyntax() error {
+
+	if !re_id_token.MatchString(id.Prefix) {
+		return fmt.Errorf("Invalid prefix '%s'", id.Prefix)
+	}
+
+	if !re_id_token.MatchString(id.Predicate) {
+		return fmt.Errorf("Invalid predicate '%s'", id.Predicate)
+	}
+
+	if strings.TrimSpace(id.Value) == "" {
+		return fmt.Errorf("Missing value")
+	}
+
+	return nil
+}
+
+// String returns the ID in the form of "{PREFIX}:{PREDICATE}={VALUE}".
+func (id *ID) String() string {
+	return fmt.Sprintf("%s:%s=%s", id.Prefix, id.Predicate, id.Value)
+}
+
+// Int64 returns the ID's value as an int64.
+func (id *ID) Int64() (int64, error) {
+	return strconv.ParseInt(id.Value, 10, 64)
+}
+
+// ConcordanceNamespace returns the concordance namespace registered for the ID's prefix.
+func (id *ID) ConcordanceNamespace() (string, error) {
+
+	id_namespaces_mu.RLock()
+	defer id_namespaces_mu.RUnlock()
+
+	ns, exists := id_namespaces[id.Prefix]
+
+	if !exists {
+		return "", fmt.Errorf("Unregistered prefix '%s'", id.Prefix)
+	}
+
+	return ns, nil
+}
+
+// ConcordanceKey returns the key, in the form of "{NAMESPACE}:{PREDICATE}", used to store the ID's value in
+// the "wof:concordances" property of Who's On First records.
+func (id *ID) ConcordanceKey() (string, error) {
+
+	ns, err := id.ConcordanceNamespace()
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s:%s", ns, id.Predicate), nil
+}
+
+// ParseWhosOnFirstId parses 'str' as an `ID` and returns its value as an int64. An error is returned if 'str'
+// is not a valid ID or is not a Who's On First ID.
+func ParseWhosOnFirstId(str string) (int64, error) {
+
+	id, err := ParseID(str)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if id.Prefix != WHOSONFIRST_PREFIX {
+		return 0, fmt.Errorf("'%s' is not a Who's On First ID", str)
+	}
+
+	return id.Int64()
+}
+
 func OvertureId(id string) string {
 	return idWithPrefix(OVERTURE_PREFIX, id)
 }
@@ -20,6 +261,19 @@ func ILMSId(id string) string {
 	return idWithPrefix(ILMS_PREFIX, id)
 }
 
+// OpenStreetMapId returns an ID for the OSM element of type 'kind' (for example "node" or "way") matching 'id'
+// in the form of "osm:{KIND}={ID}".
+func OpenStreetMapId(kind string, id string) string {
+
+	osm_id := &ID{
+		Prefix:    OPENSTREETMAP_PREFIX,
+		Predicate: kind,
+		Value:     id,
+	}
+
+	return osm_id.String()
+}
+
 func idWithPrefix(prefix string, id string) string {
-	return fmt.Sprintf("%s:id=%s", prefix, id)
+	return NewID(prefix, id).String()
 }
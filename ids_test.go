@@ -0,0 +1,125 @@
+package dedupe
+
+import (
+	"testing"
+)
+
+func TestParseID(t *testing.T) {
+
+	tests := map[string][3]string{
+		"wof:id=1234":      {WHOSONFIRST_PREFIX, "id", "1234"},
+		"ovtr:id=08f2a1=b": {OVERTURE_PREFIX, "id", "08f2a1=b"},
+	}
+
+	for str, expected := range tests {
+
+		id, err := ParseID(str)
+
+		if err != nil {
+			t.Fatalf("Failed to parse '%s', %v", str, err)
+		}
+
+		if id.Prefix != expected[0] || id.Predicate != expected[1] || id.Value != expected[2] {
+			t.Fatalf("Unexpected result parsing '%s': %v", str, id)
+		}
+
+		if id.String() != str {
+			t.Fatalf("Failed to round-trip '%s': %s", str, id.String())
+		}
+	}
+
+	invalid := []string{
+		"1234",
+		"wof:1234",
+		"wof:id=",
+		"unknown:id=1234",
+		":id=1234",
+	}
+
+	for _, str := range invalid {
+
+		_, err := ParseID(str)
+
+		if err == nil {
+			t.Fatalf("Expected '%s' to fail parsing", str)
+		}
+	}
+}
+
+func TestParseUnregisteredID(t *testing.T) {
+
+	id, err := ParseUnregisteredID("acme:id=1234")
+
+	if err != nil {
+		t.Fatalf("Failed to parse ID with unregistered prefix, %v", err)
+	}
+
+	if id.Prefix != "acme" || id.Predicate != "id" || id.Value != "1234" {
+		t.Fatalf("Unexpected result parsing ID with unregistered prefix: %v", id)
+	}
+
+	_, err = ParseID("acme:id=1234")
+
+	if err == nil {
+		t.Fatalf("Expected ParseID to fail for unregistered prefix")
+	}
+
+	for _, str := range []string{"acme:1234", "acme:id=", ":id=1234"} {
+
+		_, err := ParseUnregisteredID(str)
+
+		if err == nil {
+			t.Fatalf("Expected '%s' to fail parsing", str)
+		}
+	}
+}
+
+func TestRegisterIDPrefix(t *testing.T) {
+
+	err := RegisterIDPrefix("example", "ex")
+
+	if err != nil {
+		t.Fatalf("Failed to register prefix, %v", err)
+	}
+
+	err = RegisterIDPrefix("example", "other")
+
+	if err == nil {
+		t.Fatalf("Expected prefix registration with different namespace to fail")
+	}
+
+	id, err := ParseID("example:id=abc")
+
+	if err != nil {
+		t.Fatalf("Failed to parse ID with registered prefix, %v", err)
+	}
+
+	key, err := id.ConcordanceKey()
+
+	if err != nil {
+		t.Fatalf("Failed to derive concordance key, %v", err)
+	}
+
+	if key != "ex:id" {
+		t.Fatalf("Unexpected concordance key '%s'", key)
+	}
+}
+
+func TestParseWhosOnFirstId(t *testing.T) {
+
+	id, err := ParseWhosOnFirstId(WhosOnFirstId("1234"))
+
+	if err != nil {
+		t.Fatalf("Failed to parse WOF ID, %v", err)
+	}
+
+	if id != 1234 {
+		t.Fatalf("Unexpected WOF ID %d", id)
+	}
+
+	_, err = ParseWhosOnFirstId(OvertureId("1234"))
+
+	if err == nil {
+		t.Fatalf("Expected non-WOF ID to fail")
+	}
+}
@@ -4,17 +4,33 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"strings"
 
 	"github.com/paulmach/orb/geojson"
 	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/category"
 	"github.com/whosonfirst/go-dedupe/location"
 )
 
+// disciplines maps IMLS museum discipline codes (DISCIPL) to category names.
+var disciplines = map[string]string{
+	"ART": "art_museum",
+	"BOT": "botanical_garden",
+	"CMU": "museum",
+	"GMU": "museum",
+	"HSC": "historical_society",
+	"HST": "museum",
+	"NAT": "museum",
+	"SCI": "museum",
+	"ZAW": "zoo",
+}
+
 type ILMSVenueParser struct {
 	location.Parser
 	addr_keys []string
+	release   string
 }
 
 func init() {
@@ -28,6 +44,14 @@ func init() {
 
 func NewILMSVenueParser(ctx context.Context, uri string) (location.Parser, error) {
 
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
 	addr_keys := []string{
 		"ADSTREET",
 		"ADCITY",
@@ -37,6 +61,7 @@ func NewILMSVenueParser(ctx context.Context, uri string) (location.Parser, error
 
 	p := &ILMSVenueParser{
 		addr_keys: addr_keys,
+		release:   q.Get("release"),
 	}
 
 	return p, nil
@@ -76,10 +101,20 @@ func (p *ILMSVenueParser) Parse(ctx context.Context, body []byte) (*location.Loc
 		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing 'address' properties"))
 	}
 
-	// Something something something libpostal...
-
 	addr := strings.Join(addr_components, " ")
 
+	// The IMLS museum data files only cover the United States
+
+	components := &location.AddressComponents{
+		Street:   gjson.GetBytes(body, "properties.ADSTREET").String(),
+		Locality: gjson.GetBytes(body, "properties.ADCITY").String(),
+		Region:   gjson.GetBytes(body, "properties.ADSTATE").String(),
+		Postcode: gjson.GetBytes(body, "properties.ADZIP").String(),
+		Country:  "US",
+	}
+
+	components.ParseStreet()
+
 	geom_rsp := gjson.GetBytes(body, "geometry")
 
 	if !geom_rsp.Exists() || geom_rsp.String() == "" {
@@ -99,11 +134,20 @@ func (p *ILMSVenueParser) Parse(ctx context.Context, body []byte) (*location.Loc
 	c_id := dedupe.ILMSId(id)
 
 	c := &location.Location{
-		ID:       c_id,
-		Name:     name,
-		Address:  addr,
-		Centroid: &centroid,
+		ID:                c_id,
+		Name:              name,
+		Address:           addr,
+		Centroid:          &centroid,
+		AddressComponents: components,
+		Category:          category.Normalize(disciplines[gjson.GetBytes(body, "properties.DISCIPL").String()]),
+		Provenance: &location.Provenance{
+			Source:  "ilms",
+			Release: p.release,
+		},
 	}
 
+	c.AddPhone(gjson.GetBytes(body, "properties.PHONE").String(), components.Country)
+	c.AddWebsite(gjson.GetBytes(body, "properties.WEBURL").String())
+
 	return c, nil
 }
@@ -0,0 +1,251 @@
+package jsonpath
+
+// > go run cmd/index-locations/main.go -verbose -location-database-uri null:// -location-parser-uri 'jsonpath://?mapping=/usr/local/data/acme/mapping.json' -iterator-uri geojsonl:// /usr/local/data/acme/venues.geojsonl.bz2
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aaronland/gocloud-blob/bucket"
+	"github.com/whosonfirst/go-dedupe/iterator"
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/fileblob"
+)
+
+// GeoJSONLIterator implements the `iterator.Iterator` interface for files containing line-delimited GeoJSON features.
+type GeoJSONLIterator struct {
+	iterator.Iterator
+	// An optional bucket to read files from. If nil files are read from the local filesystem.
+	bucket      *blob.Bucket
+	max_workers int
+	start_after int
+}
+
+func init() {
+	ctx := context.Background()
+	err := iterator.RegisterIterator(ctx, "geojsonl", NewGeoJSONLIterator)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewGeoJSONLIterator returns a new `GeoJSONLIterator` instance configured by 'uri' which is expected to take the form of:
+//
+//	geojsonl://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?bucket-uri=` A valid `gocloud.dev/blob` URI to read files from. If empty files are read from the local filesystem.
+// * `?max-workers=` The maximum number of callbacks to invoke concurrently. Default is 20.
+// * `?start-after=` Skip all the lines in each file up to and including this line number.
+func NewGeoJSONLIterator(ctx context.Context, uri string) (iterator.Iterator, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	max_workers := 20
+
+	if q.Has("max-workers") {
+
+		v, err := strconv.Atoi(q.Get("max-workers"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?max-workers= parameter, %w", err)
+		}
+
+		max_workers = v
+	}
+
+	start_after := 0
+
+	if q.Has("start-after") {
+
+		v, err := strconv.Atoi(q.Get("start-after"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?start-after= parameter, %w", err)
+		}
+
+		start_after = v
+	}
+
+	iter := &GeoJSONLIterator{
+		max_workers: max_workers,
+		start_after: start_after,
+	}
+
+	bucket_uri := q.Get("bucket-uri")
+
+	if bucket_uri != "" {
+
+		source_bucket, err := bucket.OpenBucket(ctx, bucket_uri)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to open bucket, %w", err)
+		}
+
+		iter.bucket = source_bucket
+	}
+
+	return iter, nil
+}
+
+// IterateWithCallback emits each line in one or more line-delimited GeoJSON files. Files ending in ".bz2" or ".gz"
+// are decompressed using bzip2 or gzip respectively.
+func (iter *GeoJSONLIterator) IterateWithCallback(ctx context.Context, cb iterator.IteratorCallback, uris ...string) error {
+
+	throttle := make(chan bool, iter.max_workers)
+
+	for i := 0; i < iter.max_workers; i++ {
+		throttle <- true
+	}
+
+	wg := new(sync.WaitGroup)
+
+	defer wg.Wait()
+
+	for _, path := range uris {
+
+		logger := slog.Default()
+		logger = logger.With("path", path)
+
+		logger.Debug("Process file")
+
+		line_cb := func(ctx context.Context, line_number int, body []byte) {
+
+			<-throttle
+
+			wg.Add(1)
+
+			go func(line_number int, body []byte) {
+
+				defer func() {
+					wg.Done()
+					throttle <- true
+				}()
+
+				err := cb(ctx, body)
+
+				if err != nil {
+					logger.Error("Iterator callback for record failed", "line number", line_number, "error", err)
+				}
+
+			}(line_number, body)
+		}
+
+		err := iter.iteratePath(ctx, path, line_cb)
+
+		if err != nil {
+			return fmt.Errorf("Failed to iterate %s, %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (iter *GeoJSONLIterator) Close(ctx context.Context) error {
+
+	if iter.bucket != nil {
+		return iter.bucket.Close()
+	}
+
+	return nil
+}
+
+func (iter *GeoJSONLIterator) iteratePath(ctx context.Context, path string, cb func(context.Context, int, []byte)) error {
+
+	var r io.ReadCloser
+
+	if iter.bucket != nil {
+
+		bucket_r, err := iter.bucket.NewReader(ctx, path, nil)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open %s for reading, %w", path, err)
+		}
+
+		r = bucket_r
+
+	} else {
+
+		fh, err := os.Open(path)
+
+		if err != nil {
+			return fmt.Errorf("Failed to open %s for reading, %w", path, err)
+		}
+
+		r = fh
+	}
+
+	defer r.Close()
+
+	var line_r io.Reader = r
+
+	switch {
+	case strings.HasSuffix(path, ".bz2"):
+		line_r = bzip2.NewReader(bufio.NewReader(r))
+	case strings.HasSuffix(path, ".gz"):
+
+		gz_r, err := gzip.NewReader(r)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create gzip reader, %w", err)
+		}
+
+		defer gz_r.Close()
+		line_r = gz_r
+	}
+
+	// Use a bufio.Reader rather than a bufio.Scanner so that there is no upper limit on the size of a line
+
+	br := bufio.NewReader(line_r)
+	line_number := 0
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		body, err := br.ReadBytes('\n')
+
+		if err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("Failed to read line %d, %w", line_number+1, err)
+		}
+
+		if len(body) > 0 {
+
+			line_number += 1
+			body = bytes.TrimSpace(body)
+
+			if len(body) > 0 && line_number > iter.start_after {
+				cb(ctx, line_number, body)
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+	}
+
+	return nil
+}
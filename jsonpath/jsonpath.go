@@ -0,0 +1,29 @@
+// Package jsonpath provides a generic iterator for line-delimited GeoJSON files and a parser whose location
+// properties are derived from GeoJSON features using configurable gjson paths.
+//
+// https://github.com/tidwall/gjson/blob/master/SYNTAX.md
+package jsonpath
+
+import (
+	"net/url"
+
+	"github.com/whosonfirst/go-dedupe/mapping"
+)
+
+// NewMappingFromURI returns a new `mapping.Mapping` instance derived from the query parameters in 'u' whose keys
+// are gjson paths, relative to the root of a GeoJSON feature, for example "properties.name" or "properties.names.primary".
+// If the mapping does not define an ID path it defaults to "id".
+func NewMappingFromURI(u *url.URL) (*mapping.Mapping, error) {
+
+	m, err := mapping.NewMappingFromURI(u)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if m.ID == "" {
+		m.ID = "id"
+	}
+
+	return m, nil
+}
@@ -0,0 +1,138 @@
+package jsonpath
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/whosonfirst/go-dedupe/iterator"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+func TestJSONPath(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "jsonpath")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	lines := []string{
+		`{"type":"Feature","id":"a","geometry":{"type":"Point","coordinates":[-73.600050,45.524050]},"properties":{"names":{"primary":"Cafe Olimpico"},"addr":{"street":"124 Rue Saint-Viateur O","city":"Montréal","country":"CA"},"phones":["514 495 0746"],"updated":1700000000,"hours":"7-23"}}`,
+		``,
+		`{"type":"Feature","id":"b","geometry":{"type":"Point","coordinates":[-73.6,45.5]},"properties":{"names":{"primary":"No Address"}}}`,
+		`{"type":"Feature","id":"c","geometry":{"type":"Point","coordinates":[-73.6,45.5]},"properties":{"addr":{"street":"1 Main St"}}}`,
+	}
+
+	var buf bytes.Buffer
+	gz_wr := gzip.NewWriter(&buf)
+
+	for _, l := range lines {
+		fmt.Fprintln(gz_wr, l)
+	}
+
+	gz_wr.Close()
+
+	err = os.WriteFile(filepath.Join(tmp_dir, "venues.geojsonl.gz"), buf.Bytes(), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write features, %v", err)
+	}
+
+	mapping_path := filepath.Join(tmp_dir, "mapping.json")
+	mapping_body := `{"prefix":"acme","name":"properties.names.primary","street":"properties.addr.street","locality":"properties.addr.city","country":"properties.addr.country","phone":"properties.phones","lastmodified":"properties.updated"}`
+
+	err = os.WriteFile(mapping_path, []byte(mapping_body), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write mapping, %v", err)
+	}
+
+	q := url.Values{}
+	q.Set("mapping", mapping_path)
+	q.Set("custom", "hours:properties.hours")
+
+	prsr, err := location.NewParser(ctx, fmt.Sprintf("jsonpath://?%s", q.Encode()))
+
+	if err != nil {
+		t.Fatalf("Failed to create parser, %v", err)
+	}
+
+	for _, iter_uri := range []string{
+		"geojsonl://",
+		fmt.Sprintf("geojsonl://?bucket-uri=file://%s&max-workers=1", tmp_dir),
+	} {
+
+		iter, err := iterator.NewIterator(ctx, iter_uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create iterator for %s, %v", iter_uri, err)
+		}
+
+		path := "venues.geojsonl.gz"
+
+		if iter_uri == "geojsonl://" {
+			path = filepath.Join(tmp_dir, path)
+		}
+
+		locs := make([]*location.Location, 0)
+		mu := new(sync.Mutex)
+
+		iter_cb := func(ctx context.Context, body []byte) error {
+
+			loc, err := prsr.Parse(ctx, body)
+
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			locs = append(locs, loc)
+			mu.Unlock()
+
+			return nil
+		}
+
+		err = iter.IterateWithCallback(ctx, iter_cb, path)
+
+		if err != nil {
+			t.Fatalf("Failed to iterate %s, %v", iter_uri, err)
+		}
+
+		iter.Close(ctx)
+
+		if len(locs) != 1 {
+			t.Fatalf("Expected 1 location for %s, got %d", iter_uri, len(locs))
+		}
+
+		loc := locs[0]
+
+		if loc.ID != "acme:id=a" || loc.Name != "Cafe Olimpico" || loc.Address != "124 Rue Saint-Viateur O Montréal" {
+			t.Fatalf("Unexpected location: %s", loc.String())
+		}
+
+		if len(loc.Phones) != 1 || loc.Phones[0] != "+15144950746" {
+			t.Fatalf("Unexpected phones: %v", loc.Phones)
+		}
+
+		if loc.Provenance.LastModified != 1700000000 || loc.Custom["hours"] != "7-23" {
+			t.Fatalf("Unexpected provenance or custom properties: %v, %v", loc.Provenance, loc.Custom)
+		}
+	}
+
+	_, err = location.NewParser(ctx, "jsonpath://?prefix=acme&name=properties.name&custom=geohash:properties.geohash")
+
+	if err == nil {
+		t.Fatalf("Expected parser with a reserved custom key to fail")
+	}
+}
@@ -0,0 +1,111 @@
+package jsonpath
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/location"
+	"github.com/whosonfirst/go-dedupe/mapping"
+)
+
+// JSONPathParser implements the `location.Parser` interface for GeoJSON features whose properties are mapped to
+// location properties using the gjson paths defined in a `mapping.Mapping` instance.
+type JSONPathParser struct {
+	location.Parser
+	mapping *mapping.Mapping
+	release string
+}
+
+func init() {
+	ctx := context.Background()
+	err := location.RegisterParser(ctx, "jsonpath", NewJSONPathParser)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewJSONPathParser returns a new `JSONPathParser` instance configured by 'uri' which is expected to take the form of:
+//
+//	jsonpath://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be a `?mapping=` parameter pointing to a JSON-encoded `mapping.Mapping` file and/or individual
+// mapping parameters (for example `?name=properties.name`) as described in `NewMappingFromURI`. The "prefix" and
+// "name" properties are required. The prefix is registered as a valid ID prefix.
+func NewJSONPathParser(ctx context.Context, uri string) (location.Parser, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	m, err := NewMappingFromURI(u)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to derive mapping, %w", err)
+	}
+
+	err = m.Validate()
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid mapping, %w", err)
+	}
+
+	err = dedupe.RegisterIDPrefix(m.Prefix, "")
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to register prefix, %w", err)
+	}
+
+	p := &JSONPathParser{
+		mapping: m,
+		release: q.Get("release"),
+	}
+
+	return p, nil
+}
+
+func (p *JSONPathParser) Parse(ctx context.Context, body []byte) (*location.Location, error) {
+
+	geom, err := mapping.FeatureGeometry(body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return mapping.NewLocation(p.mapping, func(path string) []string {
+		return values(body, path)
+	}, geom, p.release)
+}
+
+// values returns the (string) value, or values if it is an array, of 'path' in 'body'.
+func values(body []byte, path string) []string {
+
+	values := make([]string, 0)
+
+	if path == "" {
+		return values
+	}
+
+	rsp := gjson.GetBytes(body, path)
+
+	if !rsp.Exists() {
+		return values
+	}
+
+	if !rsp.IsArray() {
+		return append(values, rsp.String())
+	}
+
+	for _, v := range rsp.Array() {
+		values = append(values, v.String())
+	}
+
+	return values
+}
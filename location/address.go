@@ -0,0 +1,93 @@
+package location
+
+import (
+	"strings"
+
+	"github.com/whosonfirst/go-dedupe/address"
+)
+
+// AddressComponents defines the structured components of a location's address.
+type AddressComponents struct {
+	// The house (or building) number of the address
+	HouseNumber string `json:"house_number,omitempty"`
+	// The name of the street of the address
+	Street string `json:"street,omitempty"`
+	// The unit, suite or apartment designator of the address
+	Unit string `json:"unit,omitempty"`
+	// The locality (city or town) of the address
+	Locality string `json:"locality,omitempty"`
+	// The region (state or province) of the address
+	Region string `json:"region,omitempty"`
+	// The postal code of the address
+	Postcode string `json:"postcode,omitempty"`
+	// The country of the address
+	Country string `json:"country,omitempty"`
+}
+
+// String returns the non-empty address components as a space-separated string.
+func (c *AddressComponents) String() string {
+
+	parts := make([]string, 0)
+
+	for _, v := range []string{
+		c.HouseNumber,
+		c.Street,
+		c.Unit,
+		c.Locality,
+		c.Region,
+		c.Postcode,
+		c.Country,
+	} {
+
+		v = strings.TrimSpace(v)
+
+		if v != "" {
+			parts = append(parts, v)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// IsEmpty returns a boolean value indicating whether all of the address components are empty.
+func (c *AddressComponents) IsEmpty() bool {
+	return c.String() == ""
+}
+
+// ParseStreet derives the house number and unit components from the street component (for example
+// "165 10th Street Ste 2") using the `address` package. Existing house number and unit values are
+// left untouched.
+func (c *AddressComponents) ParseStreet() {
+
+	if c.Street == "" || c.HouseNumber != "" {
+		return
+	}
+
+	a := address.ParseStreet(c.Street)
+
+	if a.HouseNumber == "" {
+		return
+	}
+
+	c.HouseNumber = a.HouseNumber
+	c.Street = a.Street
+
+	if c.Unit == "" {
+		c.Unit = a.Unit
+	}
+}
+
+// Normalize returns a normalized string representation of the address components using the `address` package.
+func (c *AddressComponents) Normalize() string {
+
+	a := &address.Address{
+		HouseNumber: c.HouseNumber,
+		Street:      c.Street,
+		Locality:    c.Locality,
+		Region:      c.Region,
+		Postcode:    c.Postcode,
+		Country:     c.Country,
+	}
+
+	return a.Normalize().String()
+}
@@ -0,0 +1,91 @@
+package location
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ALTERNATE_NAME_SEPARATOR is the string used to separate a location's ID from the index of one of its
+// alternate names when deriving `Location` instances for each of those names.
+const ALTERNATE_NAME_SEPARATOR string = "#name="
+
+// AlternateName defines an alternate or localized name for a location.
+type AlternateName struct {
+	// The alternate name of the location
+	Name string `json:"name"`
+	// The language of the alternate name, if known. Language codes are stored as provided by the data source.
+	Language string `json:"language,omitempty"`
+	// The kind of alternate name (for example "preferred", "variant", "common", "short", "official"), if known.
+	Kind string `json:"kind,omitempty"`
+}
+
+// Matches returns a boolean value indicating whether 'n' matches any of 'filters'. Filters are compared
+// (case-insensitively) against the language and kind of the alternate name. The filter "all" matches
+// every alternate name.
+func (n *AlternateName) Matches(filters ...string) bool {
+
+	for _, f := range filters {
+
+		f = strings.ToLower(strings.TrimSpace(f))
+
+		switch f {
+		case "":
+			continue
+		case "all", "*":
+			return true
+		case strings.ToLower(n.Language), strings.ToLower(n.Kind):
+			return true
+		}
+	}
+
+	return false
+}
+
+// AlternateNameLocations returns a list of copies of the location, one for each alternate name matching
+// 'filters' (see `AlternateName.Matches`), whose name is replaced by the alternate name. The IDs of the
+// copies take the form of "{ID}#name={INDEX}" and can be converted back to the original ID using the
+// `BaseID` method. Alternate names which are identical (case-insensitively) to the location's name, or
+// any other alternate name already included, are skipped.
+func (loc *Location) AlternateNameLocations(filters ...string) []*Location {
+
+	locations := make([]*Location, 0)
+	seen := []string{
+		strings.ToLower(loc.Name),
+	}
+
+	for idx, n := range loc.AlternateNames {
+
+		if !n.Matches(filters...) {
+			continue
+		}
+
+		k := strings.ToLower(n.Name)
+
+		if k == "" || slices.Contains(seen, k) {
+			continue
+		}
+
+		seen = append(seen, k)
+
+		alt_loc := *loc
+		alt_loc.ID = fmt.Sprintf("%s%s%d", loc.ID, ALTERNATE_NAME_SEPARATOR, idx)
+		alt_loc.Name = n.Name
+
+		locations = append(locations, &alt_loc)
+	}
+
+	return locations
+}
+
+// BaseID returns 'id' with any alternate name suffix (see `AlternateNameLocations`) removed.
+func BaseID(id string) string {
+
+	idx := strings.LastIndex(id, ALTERNATE_NAME_SEPARATOR)
+
+	if idx == -1 {
+		return id
+	}
+
+	return id[0:idx]
+}
@@ -0,0 +1,44 @@
+package location
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAlternateNameLocations(t *testing.T) {
+
+	loc := &Location{
+		ID:   "example:id=1",
+		Name: "Café Olimpico",
+		AlternateNames: []*AlternateName{
+			&AlternateName{Name: "café olimpico", Language: "fra", Kind: "preferred"},
+			&AlternateName{Name: "Cafe Olympico", Language: "eng", Kind: "variant"},
+			&AlternateName{Name: "カフェ・オリンピコ", Language: "jpn", Kind: "preferred"},
+		},
+	}
+
+	tests := map[string]int{
+		"":        0,
+		"all":     2,
+		"eng":     1,
+		"jpn,fra": 1,
+		"variant": 1,
+		"deu":     0,
+	}
+
+	for filter, expected := range tests {
+
+		alt_locs := loc.AlternateNameLocations(strings.Split(filter, ",")...)
+
+		if len(alt_locs) != expected {
+			t.Fatalf("Expected %d locations for '%s' but got %d", expected, filter, len(alt_locs))
+		}
+
+		for _, alt_loc := range alt_locs {
+
+			if BaseID(alt_loc.ID) != loc.ID {
+				t.Fatalf("Unexpected base ID for %s: %s", alt_loc.ID, BaseID(alt_loc.ID))
+			}
+		}
+	}
+}
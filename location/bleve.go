@@ -6,23 +6,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	_ "log/slog"
+	"log/slog"
 	"net/url"
 	"os"
+	"sort"
+	"strconv"
 
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
 	"github.com/blevesearch/bleve/v2/mapping"
 	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe"
 )
 
 type BleveDatabase struct {
 	index bleve.Index
+	// The number of results to fetch for each page of results when querying the index.
+	page_size int
 }
 
 type bleveDocument struct {
 	Id       string `json:"id"`
 	Geohash  string `json:"geohash"`
 	Location string `json:"location"`
+	// The location's centroid as a [lon, lat] pair, indexed as a geopoint for spatial queries
+	Centroid []float64 `json:"centroid,omitempty"`
 }
 
 func init() {
@@ -42,6 +52,31 @@ func NewBleveDatabase(ctx context.Context, uri string) (Database, error) {
 		return nil, fmt.Errorf("Failed to parse URI, %w", err)
 	}
 
+	q := u.Query()
+
+	// Bleve databases only index 5-character geohashes so adaptive blocking (see SQLDatabase) is not possible
+
+	if q.Has("max-block-size") {
+		return nil, fmt.Errorf("The ?max-block-size= parameter is not supported by %s:// databases", u.Scheme)
+	}
+
+	page_size := 1000
+
+	if q.Has("page-size") {
+
+		v, err := strconv.Atoi(q.Get("page-size"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?page-size= parameter, %w", err)
+		}
+
+		if v < 1 {
+			return nil, fmt.Errorf("Invalid ?page-size= parameter, must be greater than zero")
+		}
+
+		page_size = v
+	}
+
 	var idx bleve.Index
 	db_path := u.Path
 
@@ -55,14 +90,28 @@ func NewBleveDatabase(ctx context.Context, uri string) (Database, error) {
 			return nil, fmt.Errorf("Failed to open '%s', %w", db_path, err)
 		}
 
+		// Indexes created before geohashes and centroids had explicit mappings can be opened but will silently
+		// return no results for geohash, radius and bounding box queries
+
+		err = checkIndexMapping(v)
+
+		if err != nil {
+			v.Close()
+			return nil, fmt.Errorf("Invalid index '%s', %w, it will need to be recreated", db_path, err)
+		}
+
 		idx = v
 
 	} else {
 
-		textFieldMapping := mapping.NewTextFieldMapping()
+		// Geohashes are indexed as single (keyword) terms so they can be enumerated and matched exactly
+
+		geohashFieldMapping := mapping.NewTextFieldMapping()
+		geohashFieldMapping.Analyzer = keyword.Name
 
 		bleveMapping := bleve.NewIndexMapping()
-		bleveMapping.DefaultMapping.AddFieldMappingsAt("geohash", textFieldMapping)
+		bleveMapping.DefaultMapping.AddFieldMappingsAt("geohash", geohashFieldMapping)
+		bleveMapping.DefaultMapping.AddFieldMappingsAt("centroid", mapping.NewGeoPointFieldMapping())
 
 		v, err := bleve.New(db_path, bleveMapping)
 
@@ -74,33 +123,95 @@ func NewBleveDatabase(ctx context.Context, uri string) (Database, error) {
 	}
 
 	db := &BleveDatabase{
-		index: idx,
+		index:     idx,
+		page_size: page_size,
 	}
 
 	return db, nil
 }
 
-func (db *BleveDatabase) AddLocation(ctx context.Context, loc *Location) error {
+// checkIndexMapping ensures that 'idx' maps the "geohash" property to a keyword field and the "centroid" property
+// to a geopoint field.
+func checkIndexMapping(idx bleve.Index) error {
 
-	id := loc.ID
-	geohash := loc.Geohash()
+	idx_mapping, ok := idx.Mapping().(*mapping.IndexMappingImpl)
 
-	enc_loc, err := json.Marshal(loc)
+	if !ok || idx_mapping.DefaultMapping == nil {
+		return fmt.Errorf("Unsupported index mapping")
+	}
 
-	if err != nil {
-		return fmt.Errorf("Failed to marshal location, %w", err)
+	has_field := func(name string, is_field func(*mapping.FieldMapping) bool) bool {
+
+		doc_mapping, exists := idx_mapping.DefaultMapping.Properties[name]
+
+		if !exists {
+			return false
+		}
+
+		for _, f := range doc_mapping.Fields {
+
+			if is_field(f) {
+				return true
+			}
+		}
+
+		return false
 	}
 
-	doc := bleveDocument{
-		Id:       id,
-		Geohash:  geohash,
-		Location: string(enc_loc),
+	is_geohash := func(f *mapping.FieldMapping) bool {
+		return f.Type == "text" && f.Analyzer == keyword.Name
+	}
+
+	is_centroid := func(f *mapping.FieldMapping) bool {
+		return f.Type == "geopoint"
+	}
+
+	if !has_field("geohash", is_geohash) {
+		return fmt.Errorf("Missing keyword field mapping for 'geohash' property")
+	}
+
+	if !has_field("centroid", is_centroid) {
+		return fmt.Errorf("Missing geopoint field mapping for 'centroid' property")
+	}
+
+	return nil
+}
+
+func (db *BleveDatabase) AddLocation(ctx context.Context, loc *Location) error {
+
+	doc, err := db.documentForLocation(loc)
+
+	if err != nil {
+		return err
 	}
 
 	// slog.Info("Add", "id", loc.ID, "geohash", geohash)
 	return db.index.Index(doc.Id, doc)
 }
 
+// AddLocations adds 'locs' to the database as a single batch.
+func (db *BleveDatabase) AddLocations(ctx context.Context, locs []*Location) error {
+
+	batch := db.index.NewBatch()
+
+	for _, loc := range locs {
+
+		doc, err := db.documentForLocation(loc)
+
+		if err != nil {
+			return err
+		}
+
+		err = batch.Index(doc.Id, doc)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add location %s to batch, %w", loc.ID, err)
+		}
+	}
+
+	return db.index.Batch(batch)
+}
+
 func (db *BleveDatabase) GetById(ctx context.Context, id string) (*Location, error) {
 
 	q := bleve.NewDocIDQuery([]string{id})
@@ -127,40 +238,187 @@ func (db *BleveDatabase) GetById(ctx context.Context, id string) (*Location, err
 	return loc, nil
 }
 
+func (db *BleveDatabase) GetSourceById(ctx context.Context, id string) ([]byte, error) {
+	return nil, dedupe.NotImplemented()
+}
+
 func (db *BleveDatabase) GetGeohashes(ctx context.Context, cb GetGeohashesCallback) error {
-	return fmt.Errorf("Not implemeted")
+
+	dict, err := db.index.FieldDict("geohash")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve geohash dictionary, %w", err)
+	}
+
+	defer dict.Close()
+
+	counts := make(map[string]uint64)
+	geohashes := make([]string, 0)
+
+	for {
+
+		e, err := dict.Next()
+
+		if err != nil {
+			return fmt.Errorf("Failed to read geohash dictionary, %w", err)
+		}
+
+		if e == nil {
+			break
+		}
+
+		counts[e.Term] = e.Count
+		geohashes = append(geohashes, e.Term)
+	}
+
+	// Match the SQLDatabase implementation and return geohashes with the most locations first
+
+	sort.SliceStable(geohashes, func(i, j int) bool {
+		return counts[geohashes[i]] > counts[geohashes[j]]
+	})
+
+	for _, geohash := range geohashes {
+
+		slog.Debug("Handle geohash", "geohash", geohash)
+		err := cb(ctx, geohash)
+
+		if err != nil {
+			return fmt.Errorf("Callback failed for geohash %s, %w", geohash, err)
+		}
+	}
+
+	return nil
 }
 
 func (db *BleveDatabase) GetWithGeohash(ctx context.Context, geohash string, cb GetWithGeohashCallback) error {
 
-	q := bleve.NewMatchQuery(geohash)
+	// Geohashes are only indexed with a precision of GEOHASH_PRECISION. Shorter geohashes are queried by prefix
+	// and longer geohashes are queried by their parent and then filtered.
 
-	req := bleve.NewSearchRequest(q)
-	req.Fields = []string{"location"}
+	precision := uint(len(geohash))
 
-	rsp, err := db.index.Search(req)
+	if precision < GEOHASH_PRECISION {
+		q := bleve.NewPrefixQuery(geohash)
+		q.SetField("geohash")
+		return db.searchLocations(ctx, q, cb)
+	}
+
+	q := bleve.NewTermQuery(geohash[0:GEOHASH_PRECISION])
+	q.SetField("geohash")
+
+	if precision == GEOHASH_PRECISION {
+		return db.searchLocations(ctx, q, cb)
+	}
+
+	return db.searchLocations(ctx, q, func(ctx context.Context, loc *Location) error {
+
+		if loc.GeohashWithPrecision(precision) != geohash {
+			return nil
+		}
+
+		return cb(ctx, loc)
+	})
+}
+
+func (db *BleveDatabase) GetWithinRadius(ctx context.Context, pt orb.Point, meters float64, cb GetWithinCallback) error {
+
+	q := bleve.NewGeoDistanceQuery(pt.Lon(), pt.Lat(), fmt.Sprintf("%fm", meters))
+	q.SetField("centroid")
+
+	return db.searchLocations(ctx, q, cb)
+}
+
+func (db *BleveDatabase) GetWithinBBox(ctx context.Context, bbox orb.Bound, cb GetWithinCallback) error {
+
+	// Bounding boxes which cross the antimeridian are queried as two separate bounding boxes
+
+	queries := make([]query.Query, 0)
+
+	for _, b := range splitBound(bbox) {
+
+		bbox_q := bleve.NewGeoBoundingBoxQuery(b.Min.Lon(), b.Max.Lat(), b.Max.Lon(), b.Min.Lat())
+		bbox_q.SetField("centroid")
+
+		queries = append(queries, bbox_q)
+	}
+
+	q := bleve.NewDisjunctionQuery(queries...)
+
+	return db.searchLocations(ctx, q, cb)
+}
+
+func (db *BleveDatabase) Iterate(ctx context.Context, cb IterateCallback) error {
+	q := bleve.NewMatchAllQuery()
+	return db.searchLocations(ctx, q, cb)
+}
+
+func (db *BleveDatabase) Count(ctx context.Context) (int64, error) {
+
+	count, err := db.index.DocCount()
 
 	if err != nil {
-		return err
+		return 0, fmt.Errorf("Failed to count locations, %w", err)
 	}
 
-	for _, m := range rsp.Hits {
+	return int64(count), nil
+}
 
-		loc, err := db.locationFromDocumentMatch(m)
+func (db *BleveDatabase) RemoveLocation(ctx context.Context, id string) error {
 
-		if err != nil {
-			return err
-		}
+	err := db.index.Delete(id)
+
+	if err != nil {
+		return fmt.Errorf("Failed to remove location %s, %w", id, err)
+	}
+
+	return nil
+}
+
+// searchLocations executes 'q', fetching results one page at a time, and invokes 'cb' for each of the resulting locations.
+// Pages are fetched using the sort key (the document ID) of the last hit in the previous page rather than an offset
+// so that deep pages don't require Bleve to collect and discard all the hits that precede them.
+func (db *BleveDatabase) searchLocations(ctx context.Context, q query.Query, cb func(context.Context, *Location) error) error {
+
+	var search_after []string
+
+	for {
 
-		if loc.Geohash() != geohash {
-			continue
+		// Sort results by ID so that pagination is stable
+
+		req := bleve.NewSearchRequestOptions(q, db.page_size, 0, false)
+		req.Fields = []string{"location"}
+		req.SortBy([]string{"_id"})
+
+		if search_after != nil {
+			req.SetSearchAfter(search_after)
 		}
 
-		err = cb(ctx, loc)
+		rsp, err := db.index.SearchInContext(ctx, req)
 
 		if err != nil {
 			return err
 		}
+
+		for _, m := range rsp.Hits {
+
+			loc, err := db.locationFromDocumentMatch(m)
+
+			if err != nil {
+				return err
+			}
+
+			err = cb(ctx, loc)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(rsp.Hits) < db.page_size {
+			break
+		}
+
+		search_after = rsp.Hits[len(rsp.Hits)-1].Sort
 	}
 
 	return nil
@@ -170,6 +428,27 @@ func (db *BleveDatabase) Close(ctx context.Context) error {
 	return nil
 }
 
+func (db *BleveDatabase) documentForLocation(loc *Location) (*bleveDocument, error) {
+
+	enc_loc, err := json.Marshal(loc)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal location, %w", err)
+	}
+
+	doc := &bleveDocument{
+		Id:       loc.ID,
+		Geohash:  loc.Geohash(),
+		Location: string(enc_loc),
+	}
+
+	if loc.Centroid != nil {
+		doc.Centroid = []float64{loc.Centroid.Lon(), loc.Centroid.Lat()}
+	}
+
+	return doc, nil
+}
+
 func (db *BleveDatabase) locationFromDocumentMatch(m *search.DocumentMatch) (*Location, error) {
 
 	fields := m.Fields
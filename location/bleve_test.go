@@ -0,0 +1,208 @@
+//go:build bleve
+
+package location
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/paulmach/orb"
+)
+
+func TestBleveDatabase(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "bleve")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	// Use a small page size to make sure that results are paginated
+
+	db_uri := fmt.Sprintf("bleve://%s?page-size=2", filepath.Join(tmp_dir, "locations.db"))
+
+	db, err := NewDatabase(ctx, db_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	defer db.Close(ctx)
+
+	points := []orb.Point{
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-122.4194, 37.7749},
+	}
+
+	locs := make([]*Location, len(points))
+
+	for i, pt := range points {
+
+		locs[i] = &Location{
+			ID:       fmt.Sprintf("%d", i),
+			Name:     fmt.Sprintf("Location %d", i),
+			Centroid: &pt,
+		}
+	}
+
+	err = db.AddLocation(ctx, locs[0])
+
+	if err != nil {
+		t.Fatalf("Failed to add location, %v", err)
+	}
+
+	err = db.(BatchDatabase).AddLocations(ctx, locs[1:])
+
+	if err != nil {
+		t.Fatalf("Failed to add locations, %v", err)
+	}
+
+	geohashes := make([]string, 0)
+
+	geohashes_cb := func(ctx context.Context, geohash string) error {
+		geohashes = append(geohashes, geohash)
+		return nil
+	}
+
+	err = db.GetGeohashes(ctx, geohashes_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get geohashes, %v", err)
+	}
+
+	if len(geohashes) != 2 {
+		t.Fatalf("Unexpected number of geohashes: %v", geohashes)
+	}
+
+	if geohashes[0] != "f25dv" {
+		t.Fatalf("Expected geohash with the most locations first, got %v", geohashes)
+	}
+
+	count := 0
+
+	locations_cb := func(ctx context.Context, loc *Location) error {
+		count += 1
+		return nil
+	}
+
+	err = db.GetWithGeohash(ctx, geohashes[0], locations_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get locations with geohash, %v", err)
+	}
+
+	if count != 5 {
+		t.Fatalf("Expected 5 locations, got %d", count)
+	}
+
+	count = 0
+
+	err = db.GetWithinRadius(ctx, orb.Point{-73.601, 45.524}, 100.0, locations_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get locations within radius, %v", err)
+	}
+
+	if count != 5 {
+		t.Fatalf("Expected 5 locations within radius, got %d", count)
+	}
+
+	count = 0
+
+	err = db.GetWithinBBox(ctx, orb.Bound{Min: orb.Point{-123.0, 37.0}, Max: orb.Point{-122.0, 38.0}}, locations_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get locations within bounding box, %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 location within bounding box, got %d", count)
+	}
+
+	count = 0
+
+	err = db.GetWithinBBox(ctx, orb.Bound{Min: orb.Point{0.0, 37.0}, Max: orb.Point{-122.0, 38.0}}, locations_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get locations within bounding box across the antimeridian, %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("Expected 1 location within bounding box across the antimeridian, got %d", count)
+	}
+
+	total, err := db.Count(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to count locations, %v", err)
+	}
+
+	if total != int64(len(points)) {
+		t.Fatalf("Expected %d locations, got %d", len(points), total)
+	}
+
+	err = db.RemoveLocation(ctx, "0")
+
+	if err != nil {
+		t.Fatalf("Failed to remove location, %v", err)
+	}
+
+	count = 0
+
+	err = db.Iterate(ctx, locations_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate locations, %v", err)
+	}
+
+	if count != len(points)-1 {
+		t.Fatalf("Expected %d locations after removal, got %d", len(points)-1, count)
+	}
+}
+
+func TestBleveDatabaseMissingMapping(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "bleve")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	// An index created with the default mapping, as older versions of BleveDatabase did
+
+	db_path := filepath.Join(tmp_dir, "locations.db")
+
+	idx, err := bleve.New(db_path, bleve.NewIndexMapping())
+
+	if err != nil {
+		t.Fatalf("Failed to create index, %v", err)
+	}
+
+	err = idx.Close()
+
+	if err != nil {
+		t.Fatalf("Failed to close index, %v", err)
+	}
+
+	_, err = NewDatabase(ctx, fmt.Sprintf("bleve://%s", db_path))
+
+	if err == nil {
+		t.Fatalf("Expected index without geohash and centroid mappings to fail")
+	}
+}
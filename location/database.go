@@ -8,10 +8,12 @@ import (
 	"strings"
 
 	"github.com/aaronland/go-roster"
+	"github.com/paulmach/orb"
 )
 
 type GetWithGeohashCallback func(context.Context, *Location) error
 type GetGeohashesCallback func(context.Context, string) error
+type IterateCallback func(context.Context, *Location) error
 
 // Database is an interface for storing and querying `Location` records.
 type Database interface {
@@ -19,14 +21,43 @@ type Database interface {
 	AddLocation(context.Context, *Location) error
 	// GetById returns a `Location` record matching an identifier in the underlying database implementation.
 	GetById(context.Context, string) (*Location, error)
+	// GetSourceById returns the raw bytes of the feature that the `Location` record matching an identifier was parsed from,
+	// if they were stored, in the underlying database implementation.
+	GetSourceById(context.Context, string) ([]byte, error)
 	// GetGeohashes returns the unique set of geohashes for all the `Location` records stored in the underlying database implementation.
 	GetGeohashes(context.Context, GetGeohashesCallback) error
 	// GetWithGeohash returns all the `Location` records matching a given geohash in the underlying database implementation.
 	GetWithGeohash(context.Context, string, GetWithGeohashCallback) error
+	// GetWithinRadius returns all the `Location` records whose centroids are within a given distance, in meters, of a point in the underlying database implementation.
+	GetWithinRadius(context.Context, orb.Point, float64, GetWithinCallback) error
+	// GetWithinBBox returns all the `Location` records whose centroids are contained by a bounding box in the underlying database implementation. Bounding boxes whose minimum longitude is greater than their maximum longitude cross the antimeridian.
+	GetWithinBBox(context.Context, orb.Bound, GetWithinCallback) error
+	// Iterate invokes a callback function for every `Location` record stored in the underlying database implementation.
+	Iterate(context.Context, IterateCallback) error
+	// Count returns the number of `Location` records stored in the underlying database implementation.
+	Count(context.Context) (int64, error)
+	// RemoveLocation removes the `Location` record matching an identifier from the underlying database implementation.
+	RemoveLocation(context.Context, string) error
 	// Close performs and terminating functions required by the database.
 	Close(context.Context) error
 }
 
+// BatchDatabase is an optional interface for `Database` implementations that can add multiple `Location` records
+// at once, for example in a single transaction, which is typically much faster than adding them one at a time.
+type BatchDatabase interface {
+	Database
+	// AddLocations adds multiple `Location` records to the underlying database implementation.
+	AddLocations(context.Context, []*Location) error
+}
+
+// SourceDatabase is an optional interface for `Database` implementations that can store the raw bytes of the
+// feature a `Location` record was parsed from (its `Feature` property) for retrieval by the `GetSourceById` method.
+type SourceDatabase interface {
+	Database
+	// StoresSources returns a boolean value indicating whether the underlying database implementation is configured to store source features.
+	StoresSources() bool
+}
+
 // DatabaseInitializationFunc is a function defined by individual database package and used to create
 // an instance of that database
 type DatabaseInitializationFunc func(ctx context.Context, uri string) (Database, error)
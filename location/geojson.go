@@ -0,0 +1,82 @@
+package location
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/tidwall/gjson"
+)
+
+// AsFeature returns a GeoJSON Feature representation of the location. The feature's geometry is the location's
+// centroid (or null if the centroid is not known) and its properties are the location's JSON encoding.
+func (loc *Location) AsFeature() (*geojson.Feature, error) {
+
+	enc_loc, err := json.Marshal(loc)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal location, %w", err)
+	}
+
+	var props map[string]interface{}
+
+	err = json.Unmarshal(enc_loc, &props)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal location properties, %w", err)
+	}
+
+	var geom orb.Geometry
+
+	if loc.Centroid != nil {
+		geom = *loc.Centroid
+	}
+
+	f := geojson.NewFeature(geom)
+	f.ID = loc.ID
+	f.Properties = props
+
+	return f, nil
+}
+
+// IsFeature returns a boolean value indicating whether 'body' is a GeoJSON Feature rather than a JSON-encoded
+// `Location` instance.
+func IsFeature(body []byte) bool {
+	return gjson.GetBytes(body, "type").String() == "Feature"
+}
+
+// NewLocationFromFeature returns a new `Location` instance derived from a GeoJSON Feature produced by the `AsFeature`
+// method. If the feature's properties do not contain a centroid then the feature's (point) geometry is used instead.
+func NewLocationFromFeature(body []byte) (*Location, error) {
+
+	props_rsp := gjson.GetBytes(body, "properties")
+
+	if !props_rsp.Exists() {
+		return nil, fmt.Errorf("Feature is missing properties")
+	}
+
+	var loc *Location
+
+	err := json.Unmarshal([]byte(props_rsp.Raw), &loc)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to unmarshal feature properties, %w", err)
+	}
+
+	if loc.Centroid == nil {
+
+		coords_rsp := gjson.GetBytes(body, "geometry.coordinates")
+
+		if gjson.GetBytes(body, "geometry.type").String() == "Point" && len(coords_rsp.Array()) >= 2 {
+			pt := orb.Point{coords_rsp.Array()[0].Float(), coords_rsp.Array()[1].Float()}
+			loc.Centroid = &pt
+		}
+	}
+
+	if loc.ID == "" {
+		loc.ID = gjson.GetBytes(body, "id").String()
+	}
+
+	return loc, nil
+}
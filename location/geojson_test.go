@@ -0,0 +1,50 @@
+package location
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func TestLocationFeature(t *testing.T) {
+
+	pt := orb.Point{-73.60033, 45.524115}
+
+	loc := &Location{
+		ID:       "ovtr:id=a",
+		Name:     "Cafe Olimpico",
+		Address:  "124 Rue Saint-Viateur O",
+		Centroid: &pt,
+		Phones:   []string{"+15144950746"},
+	}
+
+	f, err := loc.AsFeature()
+
+	if err != nil {
+		t.Fatalf("Failed to derive feature, %v", err)
+	}
+
+	body, err := f.MarshalJSON()
+
+	if err != nil {
+		t.Fatalf("Failed to marshal feature, %v", err)
+	}
+
+	if !IsFeature(body) {
+		t.Fatalf("Expected body to be a feature")
+	}
+
+	loc2, err := NewLocationFromFeature(body)
+
+	if err != nil {
+		t.Fatalf("Failed to derive location from feature, %v", err)
+	}
+
+	if loc2.ID != loc.ID || loc2.Name != loc.Name || loc2.Phones[0] != loc.Phones[0] {
+		t.Fatalf("Unexpected location: %v", loc2)
+	}
+
+	if !loc2.Centroid.Equal(pt) {
+		t.Fatalf("Unexpected centroid: %v", loc2.Centroid)
+	}
+}
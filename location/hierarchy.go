@@ -0,0 +1,24 @@
+package location
+
+// Hierarchy defines the Who's On First administrative ancestors of a location. Identifiers which are not known
+// are left as zero.
+type Hierarchy struct {
+	// The Who's On First ID of the location's immediate parent
+	ParentId int64 `json:"parent_id,omitempty"`
+	// The Who's On First ID of the locality (city) containing the location
+	LocalityId int64 `json:"locality_id,omitempty"`
+	// The Who's On First ID of the region (state, province) containing the location
+	RegionId int64 `json:"region_id,omitempty"`
+	// The Who's On First ID of the country containing the location
+	CountryId int64 `json:"country_id,omitempty"`
+}
+
+// RegionId returns the Who's On First ID of the region containing the location or 0 if it is not known.
+func (loc *Location) RegionId() int64 {
+
+	if loc.Hierarchy == nil {
+		return 0
+	}
+
+	return loc.Hierarchy.RegionId
+}
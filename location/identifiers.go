@@ -0,0 +1,44 @@
+package location
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/whosonfirst/go-dedupe/identifiers"
+)
+
+// AddPhone normalizes 'raw' as an E.164 phone number, using 'country' as the default country, and appends it
+// to the location's list of phone numbers if it is valid and not already present.
+func (loc *Location) AddPhone(raw string, country string) {
+
+	phone := identifiers.NormalizePhone(raw, country)
+
+	if phone != "" && !slices.Contains(loc.Phones, phone) {
+		loc.Phones = append(loc.Phones, phone)
+	}
+}
+
+// AddWebsite derives the domain for the website URL 'raw' and appends it to the location's list of domains if
+// it is valid and not already present.
+func (loc *Location) AddWebsite(raw string) {
+
+	domain := identifiers.WebsiteDomain(raw)
+
+	if domain != "" && !slices.Contains(loc.Domains, domain) {
+		loc.Domains = append(loc.Domains, domain)
+	}
+}
+
+// HasConcordance returns a boolean value indicating whether the location has a concordance matching 'id' which
+// is expected to take the form of "{PREFIX}:{PREDICATE}={VALUE}".
+func (loc *Location) HasConcordance(id string) bool {
+
+	for k, v := range loc.Concordances {
+
+		if fmt.Sprintf("%s=%s", k, v) == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,17 +5,30 @@ import (
 	"fmt"
 	_ "log/slog"
 	"slices"
+	"strconv"
 
 	"github.com/mmcloughlin/geohash"
 	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe/address"
 	"github.com/whosonfirst/go-dedupe/embeddings"
+	"github.com/whosonfirst/go-dedupe/names"
 )
 
 // 2.4km
 const GEOHASH_PRECISION uint = 5
 
+// MIN_GEOHASH_PRECISION is the shortest geohash that database implementations supporting adaptive blocking will return.
+const MIN_GEOHASH_PRECISION uint = 4
+
+// MAX_GEOHASH_PRECISION is the longest geohash that database implementations supporting adaptive blocking will return.
+const MAX_GEOHASH_PRECISION uint = 7
+
 var reserved_metadata_keys = []string{
 	"geohash",
+	"wof:parent_id",
+	"wof:locality_id",
+	"wof:region_id",
+	"wof:country_id",
 }
 
 // Location defines a common format for locations for the purposes of deduplication
@@ -24,14 +37,35 @@ type Location struct {
 	ID string `json:"id"`
 	// The name of the location
 	Name string `json:"name"`
+	// Zero or more alternate or localized names for the location
+	AlternateNames []*AlternateName `json:"alternate_names,omitempty"`
 	// The complete address of the location
 	Address string `json:"address"`
+	// The normalized category of the location, if known. See the `category` package for details.
+	Category string `json:"category,omitempty"`
+	// The individual components of the location's address, if known
+	AddressComponents *AddressComponents `json:"address_components,omitempty"`
+	// Zero or more E.164 formatted phone numbers for the location. See the `identifiers` package for details.
+	Phones []string `json:"phones,omitempty"`
+	// Zero or more website domains for the location. See the `identifiers` package for details.
+	Domains []string `json:"domains,omitempty"`
+	// An optional dictionary of identifiers for the location in other data sources, keyed by "{PREFIX}:{PREDICATE}"
+	// (for example "ovtr:id"). The value of the key combined with its value ("{PREFIX}:{PREDICATE}={VALUE}") is
+	// expected to match the `ID` property of the corresponding location in that data source.
+	Concordances map[string]string `json:"concordances,omitempty"`
+	// Details about the origin and freshness of the location, if known
+	Provenance *Provenance `json:"provenance,omitempty"`
+	// The Who's On First administrative hierarchy for the location, if known
+	Hierarchy *Hierarchy `json:"hierarchy,omitempty"`
 	// The principal centroid for the location
 	Centroid *orb.Point `json:"centroid"`
 	// An arbitrary dictionary of custom metadata properties for the locations. There are a short list of
 	// reserved metadata keys which can be queried using the `ReservedMetadataKeys()` or `IsReservedMetadataKey(k)`
 	// methods.
 	Custom map[string]string `json:"custom,omitempty"`
+	// The raw bytes of the feature the location was parsed from, if known. This is not included in a location's
+	// JSON encoding but may be persisted separately by `Database` implementations. See `GetSourceById` for details.
+	Feature []byte `json:"-"`
 }
 
 // String returns the locations name and address as a comma-separated string.
@@ -39,6 +73,23 @@ func (loc *Location) String() string {
 	return fmt.Sprintf("%s, %s", loc.Name, loc.Address)
 }
 
+// Normalize returns a copy of the location whose name and address have been normalized using the `names`
+// and `address` packages, respectively. If the location has address components they are used to derive
+// the normalized address, otherwise the free-text address is parsed.
+func (loc *Location) Normalize() *Location {
+
+	n := *loc
+	n.Name = names.Normalize(loc.Name)
+
+	if loc.AddressComponents != nil && !loc.AddressComponents.IsEmpty() {
+		n.Address = loc.AddressComponents.Normalize()
+	} else {
+		n.Address = address.Normalize(loc.Address)
+	}
+
+	return &n
+}
+
 // Metadata returns the union of automatically derived metadata properties (geohash) and any custom metadata properties.
 func (loc *Location) Metadata() map[string]string {
 
@@ -60,7 +111,21 @@ func (loc *Location) Metadata() map[string]string {
 		m["geohash"] = loc.Geohash()
 	}
 
-	// Something something libpostal parse c.Address... and add components as metadata?
+	if loc.Hierarchy != nil {
+
+		for k, id := range map[string]int64{
+			"wof:parent_id":   loc.Hierarchy.ParentId,
+			"wof:locality_id": loc.Hierarchy.LocalityId,
+			"wof:region_id":   loc.Hierarchy.RegionId,
+			"wof:country_id":  loc.Hierarchy.CountryId,
+		} {
+
+			if id != 0 {
+				m[k] = strconv.FormatInt(id, 10)
+			}
+		}
+	}
+
 	return m
 }
 
@@ -76,10 +141,9 @@ func (loc *Location) GeohashWithPrecision(precision uint) string {
 	return geohash.EncodeWithPrecision(lat, lon, precision)
 }
 
+// Embeddings32 derives vector embeddings for the value of `String()` using 'embedder'.
 func (loc *Location) Embeddings32(ctx context.Context, embedder embeddings.Embedder) ([]float32, error) {
-
-	text := loc.String()
-	return embedder.Embeddings32(ctx, text)
+	return loc.Embeddings32WithTemplate(ctx, embedder, nil)
 }
 
 // ReservedMetadataKeys returns the list of reserved metadata keys.
@@ -0,0 +1,481 @@
+package location
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe"
+)
+
+// MemoryDatabase is an in-memory implementation of the `Database` and `BatchDatabase` interfaces. It is meant
+// for tests and small jobs which don't warrant an on-disk database or cgo drivers.
+type MemoryDatabase struct {
+	mu *sync.RWMutex
+	// Locations keyed by ID
+	locations map[string]*Location
+	// Location IDs keyed by geohash (with a precision of GEOHASH_PRECISION)
+	geohashes map[string]map[string]bool
+	// The raw bytes of each location's source feature keyed by ID
+	sources map[string][]byte
+	// The path to a JSONL file to read locations from when the database is created and to write them to when it is closed
+	snapshot string
+}
+
+func init() {
+	ctx := context.Background()
+	err := RegisterDatabase(ctx, "memory", NewMemoryDatabase)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewMemoryDatabase returns a new `MemoryDatabase` instance configured by 'uri' which is expected to take the form of:
+//
+//	memory://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?snapshot=` The path to a JSONL file of locations. If the file exists its locations are loaded when the database is created. All the locations in the database are written to the file when the `Close` method is invoked.
+func NewMemoryDatabase(ctx context.Context, uri string) (Database, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	// Locations are only grouped by 5-character geohashes so adaptive blocking is not supported
+
+	if q.Has("max-block-size") {
+		return nil, fmt.Errorf("The ?max-block-size= parameter is not supported by %s:// databases", u.Scheme)
+	}
+
+	db := &MemoryDatabase{
+		mu:        new(sync.RWMutex),
+		locations: make(map[string]*Location),
+		geohashes: make(map[string]map[string]bool),
+		sources:   make(map[string][]byte),
+		snapshot:  q.Get("snapshot"),
+	}
+
+	if db.snapshot != "" {
+
+		_, err := os.Stat(db.snapshot)
+
+		if err == nil {
+
+			err := db.loadSnapshot(ctx)
+
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return db, nil
+}
+
+func (db *MemoryDatabase) AddLocation(ctx context.Context, loc *Location) error {
+	return db.AddLocations(ctx, []*Location{loc})
+}
+
+func (db *MemoryDatabase) AddLocations(ctx context.Context, locs []*Location) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, loc := range locs {
+		db.addLocation(loc)
+	}
+
+	return nil
+}
+
+// addLocation adds (or replaces) 'loc'. It assumes the caller has acquired a write lock.
+func (db *MemoryDatabase) addLocation(loc *Location) {
+
+	// Locations added without a source feature keep any previously stored source, as with the SQLDatabase implementation
+
+	source, has_source := db.sources[loc.ID]
+
+	db.removeLocation(loc.ID)
+
+	loc = copyLocation(loc)
+	db.locations[loc.ID] = loc
+
+	if loc.Centroid != nil {
+
+		geohash := loc.Geohash()
+
+		_, exists := db.geohashes[geohash]
+
+		if !exists {
+			db.geohashes[geohash] = make(map[string]bool)
+		}
+
+		db.geohashes[geohash][loc.ID] = true
+	}
+
+	switch {
+	case len(loc.Feature) > 0:
+		db.sources[loc.ID] = loc.Feature
+	case has_source:
+		db.sources[loc.ID] = source
+	default:
+		// pass
+	}
+
+	loc.Feature = nil
+}
+
+func (db *MemoryDatabase) GetById(ctx context.Context, id string) (*Location, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	loc, exists := db.locations[id]
+
+	if !exists {
+		return nil, fmt.Errorf("Location %s does not exist, %w", id, dedupe.NotFound())
+	}
+
+	return copyLocation(loc), nil
+}
+
+func (db *MemoryDatabase) StoresSources() bool {
+	return true
+}
+
+func (db *MemoryDatabase) GetSourceById(ctx context.Context, id string) ([]byte, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	source, exists := db.sources[id]
+
+	if !exists {
+		return nil, fmt.Errorf("Location %s does not have a stored source, %w", id, dedupe.NotFound())
+	}
+
+	return source, nil
+}
+
+func (db *MemoryDatabase) GetGeohashes(ctx context.Context, cb GetGeohashesCallback) error {
+
+	db.mu.RLock()
+
+	counts := make(map[string]int)
+	geohashes := make([]string, 0)
+
+	for geohash, ids := range db.geohashes {
+		counts[geohash] = len(ids)
+		geohashes = append(geohashes, geohash)
+	}
+
+	db.mu.RUnlock()
+
+	// Match the SQLDatabase implementation and return geohashes with the most locations first
+
+	sort.Slice(geohashes, func(i, j int) bool {
+
+		if counts[geohashes[i]] == counts[geohashes[j]] {
+			return geohashes[i] < geohashes[j]
+		}
+
+		return counts[geohashes[i]] > counts[geohashes[j]]
+	})
+
+	for _, geohash := range geohashes {
+
+		err := cb(ctx, geohash)
+
+		if err != nil {
+			return fmt.Errorf("Callback failed for geohash %s, %w", geohash, err)
+		}
+	}
+
+	return nil
+}
+
+func (db *MemoryDatabase) GetWithGeohash(ctx context.Context, geohash string, cb GetWithGeohashCallback) error {
+
+	// Geohashes are only indexed with a precision of GEOHASH_PRECISION. Shorter geohashes are matched by prefix
+	// and longer geohashes are matched by their parent and then filtered.
+
+	precision := uint(len(geohash))
+
+	return db.withLocations(ctx, cb, func() []*Location {
+
+		locs := make([]*Location, 0)
+
+		for gh, ids := range db.geohashes {
+
+			switch {
+			case precision < GEOHASH_PRECISION && !strings.HasPrefix(gh, geohash):
+				continue
+			case precision >= GEOHASH_PRECISION && gh != geohash[0:GEOHASH_PRECISION]:
+				continue
+			}
+
+			for id, _ := range ids {
+
+				loc := db.locations[id]
+
+				if precision > GEOHASH_PRECISION && loc.GeohashWithPrecision(precision) != geohash {
+					continue
+				}
+
+				locs = append(locs, loc)
+			}
+		}
+
+		return locs
+	})
+}
+
+func (db *MemoryDatabase) GetWithinRadius(ctx context.Context, pt orb.Point, meters float64, cb GetWithinCallback) error {
+
+	return db.withLocations(ctx, cb, func() []*Location {
+
+		locs := make([]*Location, 0)
+
+		for _, loc := range db.locations {
+
+			if loc.Centroid != nil && distanceMeters(pt, *loc.Centroid) <= meters {
+				locs = append(locs, loc)
+			}
+		}
+
+		return locs
+	})
+}
+
+func (db *MemoryDatabase) GetWithinBBox(ctx context.Context, bbox orb.Bound, cb GetWithinCallback) error {
+
+	return db.withLocations(ctx, cb, func() []*Location {
+
+		locs := make([]*Location, 0)
+
+		for _, loc := range db.locations {
+
+			if loc.Centroid != nil && boundContains(bbox, *loc.Centroid) {
+				locs = append(locs, loc)
+			}
+		}
+
+		return locs
+	})
+}
+
+func (db *MemoryDatabase) Iterate(ctx context.Context, cb IterateCallback) error {
+
+	return db.withLocations(ctx, cb, func() []*Location {
+
+		locs := make([]*Location, 0, len(db.locations))
+
+		for _, loc := range db.locations {
+			locs = append(locs, loc)
+		}
+
+		return locs
+	})
+}
+
+func (db *MemoryDatabase) Count(ctx context.Context) (int64, error) {
+
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return int64(len(db.locations)), nil
+}
+
+func (db *MemoryDatabase) RemoveLocation(ctx context.Context, id string) error {
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.removeLocation(id)
+	return nil
+}
+
+// removeLocation removes the location matching 'id', if present. It assumes the caller has acquired a write lock.
+func (db *MemoryDatabase) removeLocation(id string) {
+
+	loc, exists := db.locations[id]
+
+	if !exists {
+		return
+	}
+
+	if loc.Centroid != nil {
+
+		geohash := loc.Geohash()
+		delete(db.geohashes[geohash], id)
+
+		if len(db.geohashes[geohash]) == 0 {
+			delete(db.geohashes, geohash)
+		}
+	}
+
+	delete(db.locations, id)
+	delete(db.sources, id)
+}
+
+func (db *MemoryDatabase) Close(ctx context.Context) error {
+
+	if db.snapshot == "" {
+		return nil
+	}
+
+	return db.writeSnapshot(ctx)
+}
+
+// withLocations invokes 'cb' for each of the locations returned by 'select_func', sorted by ID. Locations are
+// selected while holding a read lock but 'cb' is invoked after the lock has been released so that it is safe
+// to update the database from inside 'cb'.
+func (db *MemoryDatabase) withLocations(ctx context.Context, cb func(context.Context, *Location) error, select_func func() []*Location) error {
+
+	db.mu.RLock()
+	locs := select_func()
+	db.mu.RUnlock()
+
+	sort.Slice(locs, func(i, j int) bool {
+		return locs[i].ID < locs[j].ID
+	})
+
+	for _, loc := range locs {
+
+		err := cb(ctx, copyLocation(loc))
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyLocation returns a (shallow) copy of 'loc' with its own centroid so that callers which update the locations
+// passed to, or returned by, the database can not change the locations it stores or the geohashes they are indexed by.
+func copyLocation(loc *Location) *Location {
+
+	c := *loc
+
+	if loc.Centroid != nil {
+		pt := *loc.Centroid
+		c.Centroid = &pt
+	}
+
+	return &c
+}
+
+// snapshotRecord is a single line in a snapshot file. It is a JSON-encoded `Location` with an additional "source"
+// property containing the (base64-encoded) raw bytes of the location's source feature, if present, so that snapshot
+// files can also be read as plain JSONL files of `Location` records.
+type snapshotRecord struct {
+	*Location
+	Source []byte `json:"source,omitempty"`
+}
+
+func (db *MemoryDatabase) loadSnapshot(ctx context.Context) error {
+
+	r, err := os.Open(db.snapshot)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open snapshot, %w", err)
+	}
+
+	defer r.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for scanner.Scan() {
+
+		body := scanner.Bytes()
+
+		if len(body) == 0 {
+			continue
+		}
+
+		var rec snapshotRecord
+
+		err := json.Unmarshal(body, &rec)
+
+		if err != nil {
+			return fmt.Errorf("Failed to unmarshal location in snapshot, %w", err)
+		}
+
+		if rec.Location == nil {
+			return fmt.Errorf("Invalid location in snapshot, %s", string(body))
+		}
+
+		rec.Location.Feature = rec.Source
+		db.addLocation(rec.Location)
+	}
+
+	err = scanner.Err()
+
+	if err != nil {
+		return fmt.Errorf("Failed to read snapshot, %w", err)
+	}
+
+	slog.Debug("Loaded snapshot", "path", db.snapshot, "count", len(db.locations))
+	return nil
+}
+
+func (db *MemoryDatabase) writeSnapshot(ctx context.Context) error {
+
+	wr, err := os.Create(db.snapshot)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create snapshot, %w", err)
+	}
+
+	buf := bufio.NewWriter(wr)
+	enc := json.NewEncoder(buf)
+
+	cb := func(ctx context.Context, loc *Location) error {
+
+		db.mu.RLock()
+		source := db.sources[loc.ID]
+		db.mu.RUnlock()
+
+		rec := &snapshotRecord{
+			Location: loc,
+			Source:   source,
+		}
+
+		return enc.Encode(rec)
+	}
+
+	err = db.Iterate(ctx, cb)
+
+	if err != nil {
+		wr.Close()
+		return fmt.Errorf("Failed to write snapshot, %w", err)
+	}
+
+	err = buf.Flush()
+
+	if err != nil {
+		wr.Close()
+		return fmt.Errorf("Failed to flush snapshot, %w", err)
+	}
+
+	return wr.Close()
+}
@@ -0,0 +1,248 @@
+package location
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe"
+)
+
+func TestMemoryDatabase(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "memory")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	db_uri := fmt.Sprintf("memory://?snapshot=%s", filepath.Join(tmp_dir, "locations.jsonl"))
+
+	db, err := NewDatabase(ctx, db_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create database, %v", err)
+	}
+
+	points := []orb.Point{
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-122.4194, 37.7749},
+	}
+
+	locs := make([]*Location, len(points))
+
+	for i, pt := range points {
+
+		locs[i] = &Location{
+			ID:       fmt.Sprintf("%d", i),
+			Name:     fmt.Sprintf("Location %d", i),
+			Centroid: &pt,
+		}
+	}
+
+	locs[0].Feature = []byte(`{"type":"Feature"}`)
+
+	err = db.AddLocation(ctx, locs[0])
+
+	if err != nil {
+		t.Fatalf("Failed to add location, %v", err)
+	}
+
+	err = db.(BatchDatabase).AddLocations(ctx, locs[1:])
+
+	if err != nil {
+		t.Fatalf("Failed to add locations, %v", err)
+	}
+
+	geohashes := make([]string, 0)
+
+	geohashes_cb := func(ctx context.Context, geohash string) error {
+		geohashes = append(geohashes, geohash)
+		return nil
+	}
+
+	err = db.GetGeohashes(ctx, geohashes_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get geohashes, %v", err)
+	}
+
+	if len(geohashes) != 2 || geohashes[0] != "f25dv" {
+		t.Fatalf("Unexpected geohashes: %v", geohashes)
+	}
+
+	for _, geohash := range []string{"f25d", "f25dv", locs[0].GeohashWithPrecision(7)} {
+
+		count := 0
+
+		cb := func(ctx context.Context, loc *Location) error {
+			count += 1
+			return nil
+		}
+
+		err = db.GetWithGeohash(ctx, geohash, cb)
+
+		if err != nil {
+			t.Fatalf("Failed to get locations with geohash %s, %v", geohash, err)
+		}
+
+		if count != 3 {
+			t.Fatalf("Expected 3 locations for geohash %s, got %d", geohash, count)
+		}
+	}
+
+	source, err := db.GetSourceById(ctx, "0")
+
+	if err != nil {
+		t.Fatalf("Failed to get source, %v", err)
+	}
+
+	if string(source) != `{"type":"Feature"}` {
+		t.Fatalf("Unexpected source: %s", source)
+	}
+
+	// Remove locations from inside a callback to ensure that doing so does not deadlock
+
+	remove_cb := func(ctx context.Context, loc *Location) error {
+
+		if loc.ID != "2" {
+			return nil
+		}
+
+		return db.RemoveLocation(ctx, loc.ID)
+	}
+
+	err = db.Iterate(ctx, remove_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate locations, %v", err)
+	}
+
+	err = db.Close(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to close database, %v", err)
+	}
+
+	// Reopen the database from its snapshot
+
+	db, err = NewDatabase(ctx, db_uri)
+
+	if err != nil {
+		t.Fatalf("Failed to create database from snapshot, %v", err)
+	}
+
+	defer db.Close(ctx)
+
+	count, err := db.Count(ctx)
+
+	if err != nil {
+		t.Fatalf("Failed to count locations, %v", err)
+	}
+
+	if count != 3 {
+		t.Fatalf("Expected 3 locations, got %d", count)
+	}
+
+	loc, err := db.GetById(ctx, "3")
+
+	if err != nil {
+		t.Fatalf("Failed to get location, %v", err)
+	}
+
+	if loc.Name != "Location 3" {
+		t.Fatalf("Unexpected location: %s", loc.Name)
+	}
+
+	// Updating a location returned by the database should not change the location it stores
+
+	*loc.Centroid = orb.Point{0.0, 0.0}
+
+	loc, err = db.GetById(ctx, "3")
+
+	if err != nil {
+		t.Fatalf("Failed to get location, %v", err)
+	}
+
+	if *loc.Centroid != points[3] {
+		t.Fatalf("Stored location was modified: %v", loc.Centroid)
+	}
+
+	_, err = db.GetById(ctx, "2")
+
+	if !dedupe.IsNotFoundError(err) {
+		t.Fatalf("Expected not found error for removed location, got %v", err)
+	}
+
+	// Sources are written to snapshots and kept when a location is re-added without its feature
+
+	loc, err = db.GetById(ctx, "0")
+
+	if err != nil {
+		t.Fatalf("Failed to get location, %v", err)
+	}
+
+	err = db.AddLocation(ctx, loc)
+
+	if err != nil {
+		t.Fatalf("Failed to re-add location, %v", err)
+	}
+
+	source, err = db.GetSourceById(ctx, "0")
+
+	if err != nil {
+		t.Fatalf("Failed to get source from snapshot, %v", err)
+	}
+
+	if string(source) != `{"type":"Feature"}` {
+		t.Fatalf("Unexpected source from snapshot: %s", source)
+	}
+
+	within := 0
+
+	within_cb := func(ctx context.Context, loc *Location) error {
+		within += 1
+		return nil
+	}
+
+	err = db.GetWithinRadius(ctx, points[0], 1000.0, within_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get locations within radius, %v", err)
+	}
+
+	if within != 2 {
+		t.Fatalf("Expected 2 locations within radius, got %d", within)
+	}
+
+	// Radius and bounding box queries which cross the antimeridian
+
+	fiji_pt := orb.Point{179.999, -16.5}
+
+	err = db.AddLocation(ctx, &Location{ID: "4", Name: "Taveuni", Centroid: &fiji_pt})
+
+	if err != nil {
+		t.Fatalf("Failed to add location, %v", err)
+	}
+
+	within = 0
+
+	err = db.GetWithinBBox(ctx, orb.Bound{Min: orb.Point{179.0, -17.0}, Max: orb.Point{-179.0, -16.0}}, within_cb)
+
+	if err != nil {
+		t.Fatalf("Failed to get locations within bounding box, %v", err)
+	}
+
+	if within != 1 {
+		t.Fatalf("Expected 1 location within bounding box across the antimeridian, got %d", within)
+	}
+}
@@ -3,6 +3,9 @@ package location
 import (
 	"context"
 	"fmt"
+
+	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe"
 )
 
 type NullDatabase struct{}
@@ -29,6 +32,10 @@ func (db *NullDatabase) GetById(ctx context.Context, id string) (*Location, erro
 	return nil, fmt.Errorf("Not found")
 }
 
+func (db *NullDatabase) GetSourceById(ctx context.Context, id string) ([]byte, error) {
+	return nil, dedupe.NotFound()
+}
+
 func (db *NullDatabase) GetGeohashes(ctx context.Context, cb GetGeohashesCallback) error {
 	return nil
 }
@@ -37,6 +44,26 @@ func (db *NullDatabase) GetWithGeohash(ctx context.Context, geohash string, cb G
 	return nil
 }
 
+func (db *NullDatabase) GetWithinRadius(ctx context.Context, pt orb.Point, meters float64, cb GetWithinCallback) error {
+	return nil
+}
+
+func (db *NullDatabase) GetWithinBBox(ctx context.Context, bbox orb.Bound, cb GetWithinCallback) error {
+	return nil
+}
+
+func (db *NullDatabase) Iterate(ctx context.Context, cb IterateCallback) error {
+	return nil
+}
+
+func (db *NullDatabase) Count(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func (db *NullDatabase) RemoveLocation(ctx context.Context, id string) error {
+	return nil
+}
+
 func (db *NullDatabase) Close(ctx context.Context) error {
 	return nil
 }
@@ -0,0 +1,18 @@
+package location
+
+// Provenance defines details about the origin and freshness of a location.
+type Provenance struct {
+	// The name of the data source (provider) for the location, for example "whosonfirst" or "overture"
+	Source string `json:"source,omitempty"`
+	// The dataset, within the data source, for the location, for example a Who's On First repository
+	// or an All The Places spider
+	Dataset string `json:"dataset,omitempty"`
+	// The release or version of the dataset, if known
+	Release string `json:"release,omitempty"`
+	// The Unix timestamp when the location was last modified, if known
+	LastModified int64 `json:"lastmodified,omitempty"`
+	// The confidence score assigned to the location by the data source, if known
+	Confidence float64 `json:"confidence,omitempty"`
+	// The source of the location's geometry (for example Who's On First's "src:geom" property), if known
+	GeometrySource string `json:"geometry_source,omitempty"`
+}
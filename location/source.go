@@ -0,0 +1,50 @@
+package location
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// compressSource returns a gzip-compressed copy of 'body'.
+func compressSource(body []byte) ([]byte, error) {
+
+	var buf bytes.Buffer
+
+	gz := gzip.NewWriter(&buf)
+
+	_, err := gz.Write(body)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to compress source, %w", err)
+	}
+
+	err = gz.Close()
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to close compressor, %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decompressSource returns the uncompressed value of gzip-compressed 'body'.
+func decompressSource(body []byte) ([]byte, error) {
+
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create decompressor, %w", err)
+	}
+
+	defer gz.Close()
+
+	source, err := io.ReadAll(gz)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decompress source, %w", err)
+	}
+
+	return source, nil
+}
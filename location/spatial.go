@@ -0,0 +1,110 @@
+package location
+
+import (
+	"context"
+	"math"
+
+	"github.com/paulmach/orb"
+)
+
+// The mean radius of the Earth, in meters.
+const earth_radius float64 = 6371008.8
+
+// GetWithinCallback is the callback function invoked for each `Location` returned by the `GetWithinRadius` and `GetWithinBBox` methods.
+type GetWithinCallback func(context.Context, *Location) error
+
+// distanceMeters returns the great circle (haversine) distance, in meters, between 'a' and 'b'.
+func distanceMeters(a orb.Point, b orb.Point) float64 {
+
+	lat1 := deg2rad(a.Lat())
+	lat2 := deg2rad(b.Lat())
+
+	dlat := lat2 - lat1
+	dlon := deg2rad(b.Lon() - a.Lon())
+
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dlon/2), 2)
+
+	return 2 * earth_radius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// boundAroundPoint returns an `orb.Bound` which contains every point within 'meters' of 'pt'. Latitudes are clamped
+// to the poles. Bounds which cross the antimeridian are wrapped around it, in which case the bound's minimum longitude
+// is greater than its maximum longitude (see `splitBound`).
+func boundAroundPoint(pt orb.Point, meters float64) orb.Bound {
+
+	dlat := rad2deg(meters / earth_radius)
+
+	min_lat := math.Max(-90.0, pt.Lat()-dlat)
+	max_lat := math.Min(90.0, pt.Lat()+dlat)
+
+	// Bounds which contain a pole contain every longitude
+
+	dlon := 180.0
+
+	cos_lat := math.Cos(deg2rad(pt.Lat()))
+
+	if cos_lat > 0 && min_lat > -90.0 && max_lat < 90.0 {
+		dlon = math.Min(180.0, dlat/cos_lat)
+	}
+
+	if dlon >= 180.0 {
+
+		return orb.Bound{
+			Min: orb.Point{-180.0, min_lat},
+			Max: orb.Point{180.0, max_lat},
+		}
+	}
+
+	return orb.Bound{
+		Min: orb.Point{wrapLongitude(pt.Lon() - dlon), min_lat},
+		Max: orb.Point{wrapLongitude(pt.Lon() + dlon), max_lat},
+	}
+}
+
+// splitBound returns 'bbox' as one or more bounds which do not cross the antimeridian. A bound whose minimum
+// longitude is greater than its maximum longitude is assumed to cross the antimeridian and is split in two at ±180.
+func splitBound(bbox orb.Bound) []orb.Bound {
+
+	if bbox.Min.Lon() <= bbox.Max.Lon() {
+		return []orb.Bound{bbox}
+	}
+
+	return []orb.Bound{
+		orb.Bound{Min: bbox.Min, Max: orb.Point{180.0, bbox.Max.Lat()}},
+		orb.Bound{Min: orb.Point{-180.0, bbox.Min.Lat()}, Max: bbox.Max},
+	}
+}
+
+// boundContains returns true if 'pt' is contained by 'bbox', which may cross the antimeridian (see `splitBound`).
+func boundContains(bbox orb.Bound, pt orb.Point) bool {
+
+	for _, b := range splitBound(bbox) {
+
+		if b.Contains(pt) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// wrapLongitude returns 'lon' wrapped around the antimeridian to the range -180 to 180.
+func wrapLongitude(lon float64) float64 {
+
+	switch {
+	case lon > 180.0:
+		return lon - 360.0
+	case lon < -180.0:
+		return lon + 360.0
+	default:
+		return lon
+	}
+}
+
+func deg2rad(d float64) float64 {
+	return d * math.Pi / 180.0
+}
+
+func rad2deg(r float64) float64 {
+	return r * 180.0 / math.Pi
+}
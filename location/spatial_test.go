@@ -0,0 +1,61 @@
+package location
+
+import (
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func TestBoundAroundPoint(t *testing.T) {
+
+	tests := []struct {
+		pt       orb.Point
+		meters   float64
+		contains []orb.Point
+		excludes []orb.Point
+	}{
+		{
+			pt:       orb.Point{-73.601, 45.524},
+			meters:   1000.0,
+			contains: []orb.Point{orb.Point{-73.601, 45.524}, orb.Point{-73.595, 45.528}},
+			excludes: []orb.Point{orb.Point{-73.5, 45.524}, orb.Point{106.399, 45.524}},
+		},
+		{
+			pt:       orb.Point{179.999, -16.5},
+			meters:   1000.0,
+			contains: []orb.Point{orb.Point{179.995, -16.5}, orb.Point{-179.999, -16.5}},
+			excludes: []orb.Point{orb.Point{0.0, -16.5}, orb.Point{-179.9, -16.5}},
+		},
+		{
+			pt:       orb.Point{-179.999, -16.5},
+			meters:   1000.0,
+			contains: []orb.Point{orb.Point{179.999, -16.5}, orb.Point{-179.995, -16.5}},
+			excludes: []orb.Point{orb.Point{179.9, -16.5}},
+		},
+		{
+			pt:       orb.Point{0.0, 89.999},
+			meters:   1000.0,
+			contains: []orb.Point{orb.Point{180.0, 89.999}, orb.Point{-90.0, 89.999}},
+			excludes: []orb.Point{orb.Point{0.0, 89.9}},
+		},
+	}
+
+	for _, test := range tests {
+
+		bbox := boundAroundPoint(test.pt, test.meters)
+
+		for _, pt := range test.contains {
+
+			if !boundContains(bbox, pt) {
+				t.Fatalf("Expected bound %v around %v to contain %v", bbox, test.pt, pt)
+			}
+		}
+
+		for _, pt := range test.excludes {
+
+			if boundContains(bbox, pt) {
+				t.Fatalf("Expected bound %v around %v to exclude %v", bbox, test.pt, pt)
+			}
+		}
+	}
+}
@@ -8,7 +8,10 @@ import (
 	"log/slog"
 	"net/url"
 	"strconv"
+	"strings"
 
+	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe"
 	"github.com/whosonfirst/go-dedupe/database"
 )
 
@@ -16,6 +19,15 @@ type SQLDatabase struct {
 	conn   *sql.DB
 	engine string
 	dsn    string
+	// If true the (compressed) raw bytes of each location's source feature are stored in the "source" column.
+	store_source bool
+	// If true the locations table has a "source" column. Locations are written using a statement which only replaces
+	// a previously stored source if the location being written has a source feature of its own (see insertQuery) so
+	// adding a location without its feature, for example when enriching locations, does not discard a stored source.
+	has_source bool
+	// If greater than zero the maximum number of locations in each geohash returned by `GetGeohashes`. See
+	// `getAdaptiveGeohashes` for details.
+	max_block_size int
 }
 
 func init() {
@@ -46,10 +58,38 @@ func NewSQLDatabase(ctx context.Context, uri string) (Database, error) {
 		return nil, fmt.Errorf("Failed to open database connection, %w", err)
 	}
 
+	store_source := false
+
+	if q.Has("store-source") {
+
+		v, err := strconv.ParseBool(q.Get("store-source"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?store-source= parameter, %w", err)
+		}
+
+		store_source = v
+	}
+
+	max_block_size := 0
+
+	if q.Has("max-block-size") {
+
+		v, err := strconv.Atoi(q.Get("max-block-size"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?max-block-size= parameter, %w", err)
+		}
+
+		max_block_size = v
+	}
+
 	db := &SQLDatabase{
-		engine: engine,
-		conn:   conn,
-		dsn:    dsn,
+		engine:         engine,
+		conn:           conn,
+		dsn:            dsn,
+		store_source:   store_source,
+		max_block_size: max_block_size,
 	}
 
 	opts := database.DefaultConfigureSQLDatabaseOptions()
@@ -58,7 +98,7 @@ func NewSQLDatabase(ctx context.Context, uri string) (Database, error) {
 	opts.Tables = []*database.SQLTable{
 		&database.SQLTable{
 			Name:   "locations",
-			Schema: "CREATE TABLE locations (id TEXT PRIMARY KEY, geohash TEXT, body TEXT); CREATE INDEX locations_by_geohash ON locations (geohash);",
+			Schema: "CREATE TABLE locations (id TEXT PRIMARY KEY, geohash TEXT, latitude REAL, longitude REAL, geohash_4 TEXT, geohash_6 TEXT, geohash_7 TEXT, body TEXT, source BLOB); CREATE INDEX locations_by_geohash ON locations (geohash); CREATE INDEX locations_by_coordinates ON locations (latitude, longitude); CREATE INDEX locations_by_geohash_4 ON locations (geohash_4); CREATE INDEX locations_by_geohash_6 ON locations (geohash_6); CREATE INDEX locations_by_geohash_7 ON locations (geohash_7);",
 		},
 	}
 
@@ -68,6 +108,38 @@ func NewSQLDatabase(ctx context.Context, uri string) (Database, error) {
 		return nil, err
 	}
 
+	// Databases created before the "source" column was introduced need to be updated in order to store sources
+
+	if store_source {
+
+		_, err := db.ensureColumn(ctx, "source", "BLOB")
+
+		if err != nil {
+			return nil, err
+		}
+
+		db.has_source = true
+
+	} else {
+
+		v, err := db.hasColumn(ctx, "source")
+
+		if err != nil {
+			return nil, err
+		}
+
+		db.has_source = v
+	}
+
+	// Databases created before the "latitude", "longitude" and "geohash_{N}" columns were introduced need to be
+	// updated (and backfilled) in order to support spatial queries and adaptive blocking
+
+	err = db.ensureDerivedColumns(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
 	switch engine {
 	case "sqlite3":
 
@@ -101,21 +173,60 @@ func (db *SQLDatabase) String() string {
 
 func (db *SQLDatabase) AddLocation(ctx context.Context, loc *Location) error {
 
-	id := loc.ID
-	geohash := loc.Geohash()
+	args, err := db.insertArgs(loc)
 
-	enc_loc, err := json.Marshal(loc)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.conn.ExecContext(ctx, db.insertQuery(), args...)
 
 	if err != nil {
-		return fmt.Errorf("Failed to marshal location, %w", err)
+		return fmt.Errorf("Failed to add location, %w", err)
 	}
 
-	q := "INSERT OR REPLACE INTO locations (id, geohash, body) VALUES (?, ?, ?)"
+	return nil
+}
+
+// AddLocations adds 'locs' to the database using a single transaction and prepared statement.
+func (db *SQLDatabase) AddLocations(ctx context.Context, locs []*Location) error {
 
-	_, err = db.conn.ExecContext(ctx, q, id, geohash, string(enc_loc))
+	tx, err := db.conn.BeginTx(ctx, nil)
 
 	if err != nil {
-		return fmt.Errorf("Failed to add location, %w", err)
+		return fmt.Errorf("Failed to begin transaction, %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, db.insertQuery())
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to prepare statement, %w", err)
+	}
+
+	defer stmt.Close()
+
+	for _, loc := range locs {
+
+		args, err := db.insertArgs(loc)
+
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = stmt.ExecContext(ctx, args...)
+
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Failed to add location %s, %w", loc.ID, err)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction, %w", err)
 	}
 
 	return nil
@@ -146,8 +257,46 @@ func (db *SQLDatabase) GetById(ctx context.Context, id string) (*Location, error
 	return loc, nil
 }
 
+func (db *SQLDatabase) StoresSources() bool {
+	return db.store_source
+}
+
+func (db *SQLDatabase) GetSourceById(ctx context.Context, id string) ([]byte, error) {
+
+	if !db.has_source {
+		return nil, fmt.Errorf("Location %s does not have a stored source, %w", id, dedupe.NotFound())
+	}
+
+	q := "SELECT source FROM locations WHERE id = ?"
+
+	row := db.conn.QueryRowContext(ctx, q, id)
+
+	var source []byte
+
+	err := row.Scan(&source)
+
+	switch {
+	case err == sql.ErrNoRows:
+		return nil, fmt.Errorf("Location %s does not exist, %w", id, dedupe.NotFound())
+	case err != nil:
+		return nil, err
+	default:
+		// pass
+	}
+
+	if len(source) == 0 {
+		return nil, fmt.Errorf("Location %s does not have a stored source, %w", id, dedupe.NotFound())
+	}
+
+	return decompressSource(source)
+}
+
 func (db *SQLDatabase) GetGeohashes(ctx context.Context, cb GetGeohashesCallback) error {
 
+	if db.max_block_size > 0 {
+		return db.getAdaptiveGeohashes(ctx, cb)
+	}
+
 	// To do: Make ASC / DESC a config option
 
 	q := "SELECT geohash, COUNT(id) AS count FROM locations GROUP BY geohash ORDER BY count DESC"
@@ -183,12 +332,243 @@ func (db *SQLDatabase) GetGeohashes(ctx context.Context, cb GetGeohashesCallback
 	return rows.Err()
 }
 
+// getAdaptiveGeohashes returns geohashes whose precision varies according to the density of locations they
+// contain. Geohashes are enumerated starting at `MIN_GEOHASH_PRECISION` so that sparse areas are compared as a
+// single, coarser, block. Any geohash containing more than 'max_block_size' locations is split into its child
+// geohashes, and so on, until `MAX_GEOHASH_PRECISION` is reached. All the children of a split geohash are returned,
+// however few locations they contain; sparse children are not merged back in to their parent.
+func (db *SQLDatabase) getAdaptiveGeohashes(ctx context.Context, cb GetGeohashesCallback) error {
+
+	counts, err := db.countGeohashes(ctx, "")
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+
+		err := db.splitGeohash(ctx, c.geohash, c.count, cb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *SQLDatabase) splitGeohash(ctx context.Context, geohash string, count int, cb GetGeohashesCallback) error {
+
+	if count <= db.max_block_size || uint(len(geohash)) >= MAX_GEOHASH_PRECISION {
+
+		slog.Debug("Handle geohash", "geohash", geohash, "count", count)
+		err := cb(ctx, geohash)
+
+		if err != nil {
+			return fmt.Errorf("Callback failed for geohash %s, %w", geohash, err)
+		}
+
+		return nil
+	}
+
+	slog.Debug("Split geohash", "geohash", geohash, "count", count)
+
+	counts, err := db.countGeohashes(ctx, geohash)
+
+	if err != nil {
+		return err
+	}
+
+	for _, c := range counts {
+
+		err := db.splitGeohash(ctx, c.geohash, c.count, cb)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type geohashCount struct {
+	geohash string
+	count   int
+}
+
+// countGeohashes returns the number of locations in each of the child geohashes of 'parent', ordered by count.
+// If 'parent' is empty then counts for all the geohashes with a precision of `MIN_GEOHASH_PRECISION` are returned.
+// Results are read in full before returning since databases may be limited to a single connection.
+func (db *SQLDatabase) countGeohashes(ctx context.Context, parent string) ([]*geohashCount, error) {
+
+	var q string
+	args := make([]any, 0)
+
+	if parent == "" {
+
+		col := geohashColumn(MIN_GEOHASH_PRECISION)
+		q = fmt.Sprintf("SELECT %s, COUNT(id) AS count FROM locations WHERE %s IS NOT NULL GROUP BY %s ORDER BY count DESC", col, col, col)
+
+	} else {
+
+		parent_col := geohashColumn(uint(len(parent)))
+		child_col := geohashColumn(uint(len(parent)) + 1)
+
+		q = fmt.Sprintf("SELECT %s, COUNT(id) AS count FROM locations WHERE %s = ? GROUP BY %s ORDER BY count DESC", child_col, parent_col, child_col)
+		args = append(args, parent)
+	}
+
+	slog.Debug("Count geohashes", "query", q, "parent", parent)
+
+	rows, err := db.conn.QueryContext(ctx, q, args...)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to count geohashes, %w", err)
+	}
+
+	defer rows.Close()
+
+	counts := make([]*geohashCount, 0)
+
+	for rows.Next() {
+
+		c := new(geohashCount)
+
+		err := rows.Scan(&c.geohash, &c.count)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to scan geohash count, %w", err)
+		}
+
+		counts = append(counts, c)
+	}
+
+	err = rows.Err()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
+
+// geohashColumn returns the name of the column storing geohashes with 'precision' characters.
+func geohashColumn(precision uint) string {
+
+	if precision == GEOHASH_PRECISION {
+		return "geohash"
+	}
+
+	return fmt.Sprintf("geohash_%d", precision)
+}
+
 func (db *SQLDatabase) GetWithGeohash(ctx context.Context, geohash string, cb GetWithGeohashCallback) error {
 
-	q := "SELECT body FROM locations WHERE geohash = ?"
+	precision := uint(len(geohash))
+
+	if precision < MIN_GEOHASH_PRECISION || precision > MAX_GEOHASH_PRECISION {
+		return fmt.Errorf("Unsupported geohash precision (%d) for %s", precision, geohash)
+	}
+
+	q := fmt.Sprintf("SELECT body FROM locations WHERE %s = ?", geohashColumn(precision))
 	slog.Debug("Get with geohash", "query", q, "geohash", geohash, "database", db)
 
-	rows, err := db.conn.QueryContext(ctx, q, geohash)
+	return db.queryLocations(ctx, q, func(ctx context.Context, loc *Location) error {
+		slog.Debug("Process location for geohash", "geohash", geohash, "location", loc.String())
+		return cb(ctx, loc)
+	}, geohash)
+}
+
+func (db *SQLDatabase) GetWithinRadius(ctx context.Context, pt orb.Point, meters float64, cb GetWithinCallback) error {
+
+	// Query the bounding box containing the circle and then filter the results by their actual distance
+
+	bbox := boundAroundPoint(pt, meters)
+
+	return db.GetWithinBBox(ctx, bbox, func(ctx context.Context, loc *Location) error {
+
+		if distanceMeters(pt, *loc.Centroid) > meters {
+			return nil
+		}
+
+		return cb(ctx, loc)
+	})
+}
+
+func (db *SQLDatabase) GetWithinBBox(ctx context.Context, bbox orb.Bound, cb GetWithinCallback) error {
+
+	q := "SELECT body FROM locations WHERE latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?"
+
+	locations_cb := func(ctx context.Context, loc *Location) error {
+
+		if loc.Centroid == nil {
+			return nil
+		}
+
+		return cb(ctx, loc)
+	}
+
+	// Bounding boxes which cross the antimeridian are queried as two separate bounding boxes
+
+	for _, b := range splitBound(bbox) {
+
+		slog.Debug("Get within bounding box", "query", q, "bbox", b, "database", db)
+
+		err := db.queryLocations(ctx, q, locations_cb, b.Min.Lat(), b.Max.Lat(), b.Min.Lon(), b.Max.Lon())
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Iterate invokes 'cb' for every location in the database. Databases limited to a single connection should not
+// be queried or updated from inside 'cb'.
+func (db *SQLDatabase) Iterate(ctx context.Context, cb IterateCallback) error {
+
+	q := "SELECT body FROM locations"
+	slog.Debug("Iterate locations", "query", q, "database", db)
+
+	return db.queryLocations(ctx, q, cb)
+}
+
+func (db *SQLDatabase) Count(ctx context.Context) (int64, error) {
+
+	q := "SELECT COUNT(id) FROM locations"
+
+	row := db.conn.QueryRowContext(ctx, q)
+
+	var count int64
+
+	err := row.Scan(&count)
+
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count locations, %w", err)
+	}
+
+	return count, nil
+}
+
+func (db *SQLDatabase) RemoveLocation(ctx context.Context, id string) error {
+
+	q := "DELETE FROM locations WHERE id = ?"
+
+	_, err := db.conn.ExecContext(ctx, q, id)
+
+	if err != nil {
+		return fmt.Errorf("Failed to remove location %s, %w", id, err)
+	}
+
+	return nil
+}
+
+// queryLocations executes 'q' with 'args', where 'q' is expected to select a single "body" column, and invokes
+// 'cb' for each of the resulting locations.
+func (db *SQLDatabase) queryLocations(ctx context.Context, q string, cb func(context.Context, *Location) error, args ...any) error {
+
+	rows, err := db.conn.QueryContext(ctx, q, args...)
 
 	if err != nil {
 		slog.Error("Failed to query", "error", err)
@@ -217,7 +597,6 @@ func (db *SQLDatabase) GetWithGeohash(ctx context.Context, geohash string, cb Ge
 			return err
 		}
 
-		slog.Debug("Process location for geohash", "geohash", geohash, "location", loc.String())
 		err = cb(ctx, loc)
 
 		if err != nil {
@@ -228,6 +607,239 @@ func (db *SQLDatabase) GetWithGeohash(ctx context.Context, geohash string, cb Ge
 	return rows.Err()
 }
 
+func (db *SQLDatabase) insertQuery() string {
+
+	if db.has_source {
+		return "INSERT OR REPLACE INTO locations (id, geohash, latitude, longitude, geohash_4, geohash_6, geohash_7, body, source) VALUES (?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, (SELECT source FROM locations WHERE id = ?)))"
+	}
+
+	return "INSERT OR REPLACE INTO locations (id, geohash, latitude, longitude, geohash_4, geohash_6, geohash_7, body) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"
+}
+
+func (db *SQLDatabase) insertArgs(loc *Location) ([]any, error) {
+
+	id := loc.ID
+	geohash := loc.Geohash()
+
+	enc_loc, err := json.Marshal(loc)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to marshal location, %w", err)
+	}
+
+	args := []any{
+		id,
+		geohash,
+	}
+
+	args = append(args, derivedColumnValues(loc)...)
+	args = append(args, string(enc_loc))
+
+	if !db.has_source {
+		return args, nil
+	}
+
+	// Use an untyped nil, rather than a nil []byte which some drivers (DuckDB) bind as an empty blob,
+	// so that the existing source is selected (see insertQuery) when there is no feature
+
+	var source any
+
+	if len(loc.Feature) > 0 {
+
+		v, err := compressSource(loc.Feature)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to compress source for %s, %w", id, err)
+		}
+
+		source = v
+	}
+
+	// The location's ID is passed a second time in order to select the existing source
+
+	args = append(args, source, id)
+	return args, nil
+}
+
+// hasColumn returns a boolean value indicating whether the locations table has a column named 'name'.
+func (db *SQLDatabase) hasColumn(ctx context.Context, name string) (bool, error) {
+
+	q := "SELECT COUNT(name) FROM pragma_table_info('locations') WHERE name = ?"
+
+	row := db.conn.QueryRowContext(ctx, q, name)
+
+	var count int
+
+	err := row.Scan(&count)
+
+	if err != nil {
+		return false, fmt.Errorf("Failed to determine whether %s column exists, %w", name, err)
+	}
+
+	return count > 0, nil
+}
+
+// ensureColumn adds a column named 'name' of type 'col_type' to the locations table if it does not already exist.
+// It returns a boolean value indicating whether the column was added.
+func (db *SQLDatabase) ensureColumn(ctx context.Context, name string, col_type string) (bool, error) {
+
+	exists, err := db.hasColumn(ctx, name)
+
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		return false, nil
+	}
+
+	q := fmt.Sprintf("ALTER TABLE locations ADD COLUMN %s %s", name, col_type)
+	slog.Debug("Add column", "query", q)
+
+	_, err = db.conn.ExecContext(ctx, q)
+
+	if err != nil {
+		return false, fmt.Errorf("Failed to add %s column, %w", name, err)
+	}
+
+	return true, nil
+}
+
+// The columns, and their types, whose values are derived from a location's centroid. See `derivedColumnValues` for details.
+var derived_columns = [][2]string{
+	{"latitude", "REAL"},
+	{"longitude", "REAL"},
+	{"geohash_4", "TEXT"},
+	{"geohash_6", "TEXT"},
+	{"geohash_7", "TEXT"},
+}
+
+// derivedColumnValues returns the values for the columns listed in 'derived_columns', in order, for 'loc'.
+// Locations without a centroid are stored with NULL values and excluded from spatial queries and adaptive blocking.
+func derivedColumnValues(loc *Location) []any {
+
+	if loc.Centroid == nil {
+		return []any{nil, nil, nil, nil, nil}
+	}
+
+	return []any{
+		loc.Centroid.Lat(),
+		loc.Centroid.Lon(),
+		loc.GeohashWithPrecision(4),
+		loc.GeohashWithPrecision(6),
+		loc.GeohashWithPrecision(7),
+	}
+}
+
+// ensureDerivedColumns adds the columns listed in 'derived_columns' to the locations table, if necessary, and
+// populates them from the centroids of any existing locations.
+func (db *SQLDatabase) ensureDerivedColumns(ctx context.Context) error {
+
+	added := false
+
+	for _, c := range derived_columns {
+
+		ok, err := db.ensureColumn(ctx, c[0], c[1])
+
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			added = true
+		}
+	}
+
+	if !added {
+		return nil
+	}
+
+	indices := []string{
+		"CREATE INDEX IF NOT EXISTS locations_by_coordinates ON locations (latitude, longitude)",
+		"CREATE INDEX IF NOT EXISTS locations_by_geohash_4 ON locations (geohash_4)",
+		"CREATE INDEX IF NOT EXISTS locations_by_geohash_6 ON locations (geohash_6)",
+		"CREATE INDEX IF NOT EXISTS locations_by_geohash_7 ON locations (geohash_7)",
+	}
+
+	for _, q := range indices {
+
+		slog.Debug("Add index", "query", q)
+
+		_, err := db.conn.ExecContext(ctx, q)
+
+		if err != nil {
+			return fmt.Errorf("Failed to create index, %w", err)
+		}
+	}
+
+	// Read all the locations before updating anything since databases may be limited to a single connection
+
+	locations := make([]*Location, 0)
+
+	err := db.queryLocations(ctx, "SELECT body FROM locations", func(ctx context.Context, loc *Location) error {
+
+		if loc.Centroid != nil {
+			locations = append(locations, &Location{ID: loc.ID, Centroid: loc.Centroid})
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("Failed to read existing locations, %w", err)
+	}
+
+	if len(locations) == 0 {
+		return nil
+	}
+
+	slog.Info("Backfill derived columns for existing locations", "count", len(locations))
+
+	set := make([]string, len(derived_columns))
+
+	for i, c := range derived_columns {
+		set[i] = fmt.Sprintf("%s = ?", c[0])
+	}
+
+	q := fmt.Sprintf("UPDATE locations SET %s WHERE id = ?", strings.Join(set, ", "))
+
+	tx, err := db.conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return fmt.Errorf("Failed to begin transaction, %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, q)
+
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("Failed to prepare statement, %w", err)
+	}
+
+	defer stmt.Close()
+
+	for _, loc := range locations {
+
+		args := derivedColumnValues(loc)
+		args = append(args, loc.ID)
+
+		_, err := stmt.ExecContext(ctx, args...)
+
+		if err != nil {
+			tx.Rollback()
+			return fmt.Errorf("Failed to update derived columns for %s, %w", loc.ID, err)
+		}
+	}
+
+	err = tx.Commit()
+
+	if err != nil {
+		return fmt.Errorf("Failed to commit transaction, %w", err)
+	}
+
+	return nil
+}
+
 func (db *SQLDatabase) Close(ctx context.Context) error {
 	return db.conn.Close()
 }
@@ -26,3 +26,14 @@ func TestDuckDBDatabase(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestDuckDBDatabasePreserveSources(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := testSQLDatabaseEnginePreserveSources(ctx, "duckdb")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
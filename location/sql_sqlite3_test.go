@@ -19,3 +19,25 @@ func TestSQLite3Database(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSQLite3DatabaseAdaptiveGeohashes(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := testSQLDatabaseEngineAdaptiveGeohashes(ctx, "sqlite3")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSQLite3DatabasePreserveSources(t *testing.T) {
+
+	ctx := context.Background()
+
+	err := testSQLDatabaseEnginePreserveSources(ctx, "sqlite3")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+}
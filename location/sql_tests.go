@@ -5,17 +5,219 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 
 	"github.com/paulmach/orb"
+	"github.com/whosonfirst/go-dedupe"
 )
 
 func testSQLDatabaseEngine(ctx context.Context, engine string) error {
 
+	tmp_path, err := tempDatabasePath(engine)
+
+	if err != nil {
+		return err
+	}
+
+	db_uri := fmt.Sprintf("sql://%s?dsn=%s&store-source=true", engine, tmp_path)
+	slog.Debug(db_uri)
+
+	db, err := NewDatabase(ctx, db_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new database for %s, %v", db_uri, err)
+	}
+
+	pt := orb.Point([]float64{-73.60033, 45.524115})
+
+	loc := &Location{
+		ID:       "1",
+		Name:     "Open Da Night",
+		Address:  "124 rue St. Viateur o. Montreal",
+		Centroid: &pt,
+		AddressComponents: &AddressComponents{
+			HouseNumber: "124",
+			Street:      "rue St. Viateur o.",
+			Locality:    "Montreal",
+			Region:      "QC",
+			Country:     "CA",
+		},
+		Feature: []byte(`{"type":"Feature","properties":{"name":"Open Da Night"}}`),
+	}
+
+	err = db.AddLocation(ctx, loc)
+
+	if err != nil {
+		return fmt.Errorf("Failed to add location, %w", err)
+	}
+
+	loc2, err := db.GetById(ctx, "1")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve location, %w", err)
+	}
+
+	if loc2.AddressComponents == nil {
+		return fmt.Errorf("Retrieved location is missing address components")
+	}
+
+	if *loc2.AddressComponents != *loc.AddressComponents {
+		return fmt.Errorf("Unexpected address components for retrieved location: %v", loc2.AddressComponents)
+	}
+
+	source, err := db.GetSourceById(ctx, "1")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve source, %w", err)
+	}
+
+	if string(source) != string(loc.Feature) {
+		return fmt.Errorf("Unexpected source for retrieved location: %s", string(source))
+	}
+
+	batch_db, ok := db.(BatchDatabase)
+
+	if !ok {
+		return fmt.Errorf("Database does not implement BatchDatabase")
+	}
+
+	batch := []*Location{
+		&Location{ID: "2", Name: "St-Viateur Bagel", Centroid: &pt},
+		&Location{ID: "3", Name: "Fairmount Bagel", Centroid: &pt},
+	}
+
+	err = batch_db.AddLocations(ctx, batch)
+
+	if err != nil {
+		return fmt.Errorf("Failed to add locations, %w", err)
+	}
+
+	loc3, err := db.GetById(ctx, "3")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve batched location, %w", err)
+	}
+
+	if loc3.Name != "Fairmount Bagel" {
+		return fmt.Errorf("Unexpected name for batched location: %s", loc3.Name)
+	}
+
+	count := 0
+
+	within_cb := func(ctx context.Context, loc *Location) error {
+		count += 1
+		return nil
+	}
+
+	err = db.GetWithinRadius(ctx, orb.Point{-73.601, 45.524}, 100.0, within_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to get locations within radius, %w", err)
+	}
+
+	if count != 3 {
+		return fmt.Errorf("Expected 3 locations within radius, got %d", count)
+	}
+
+	count = 0
+
+	err = db.GetWithinBBox(ctx, orb.Bound{Min: orb.Point{-74.0, 45.0}, Max: orb.Point{-73.7, 45.5}}, within_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to get locations within bounding box, %w", err)
+	}
+
+	if count != 0 {
+		return fmt.Errorf("Expected 0 locations within bounding box, got %d", count)
+	}
+
+	// Radius and bounding box queries which cross the antimeridian
+
+	fiji_pt := orb.Point{179.999, -16.5}
+
+	err = db.AddLocation(ctx, &Location{ID: "4", Name: "Taveuni", Centroid: &fiji_pt})
+
+	if err != nil {
+		return fmt.Errorf("Failed to add location, %w", err)
+	}
+
+	count = 0
+
+	err = db.GetWithinRadius(ctx, orb.Point{-179.999, -16.5}, 1000.0, within_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to get locations within radius across the antimeridian, %w", err)
+	}
+
+	if count != 1 {
+		return fmt.Errorf("Expected 1 location within radius across the antimeridian, got %d", count)
+	}
+
+	count = 0
+
+	err = db.GetWithinBBox(ctx, orb.Bound{Min: orb.Point{179.0, -17.0}, Max: orb.Point{-179.0, -16.0}}, within_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to get locations within bounding box across the antimeridian, %w", err)
+	}
+
+	if count != 1 {
+		return fmt.Errorf("Expected 1 location within bounding box across the antimeridian, got %d", count)
+	}
+
+	err = db.RemoveLocation(ctx, "4")
+
+	if err != nil {
+		return fmt.Errorf("Failed to remove location, %w", err)
+	}
+
+	err = db.RemoveLocation(ctx, "2")
+
+	if err != nil {
+		return fmt.Errorf("Failed to remove location, %w", err)
+	}
+
+	total, err := db.Count(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to count locations, %w", err)
+	}
+
+	if total != 2 {
+		return fmt.Errorf("Expected 2 locations after removal, got %d", total)
+	}
+
+	count = 0
+
+	err = db.Iterate(ctx, within_cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to iterate locations, %w", err)
+	}
+
+	if count != 2 {
+		return fmt.Errorf("Expected to iterate 2 locations, got %d", count)
+	}
+
+	// To do: GetByGeohash, etc.
+
+	err = db.Close(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to close database for %s, %v", db_uri, err)
+	}
+
+	return nil
+}
+
+// tempDatabasePath returns the path for a new (not yet created) temporary database file for 'engine'.
+func tempDatabasePath(engine string) (string, error) {
+
 	suffix := fmt.Sprintf("*-%s.db", engine)
 	f, err := os.CreateTemp("", suffix)
 
 	if err != nil {
-		return fmt.Errorf("Failed to create temp file for %s, %v", suffix, err)
+		return "", fmt.Errorf("Failed to create temp file for %s, %v", suffix, err)
 	}
 
 	tmp_path := f.Name()
@@ -26,17 +228,27 @@ func testSQLDatabaseEngine(ctx context.Context, engine string) error {
 	err = f.Close()
 
 	if err != nil {
-		return fmt.Errorf("Failed to close temp file, %w", err)
+		return "", fmt.Errorf("Failed to close temp file, %w", err)
 	}
 
 	err = os.Remove(tmp_path)
 
 	if err != nil {
-		return fmt.Errorf("Failed to remove temp file, %w", err)
+		return "", fmt.Errorf("Failed to remove temp file, %w", err)
 	}
 
-	db_uri := fmt.Sprintf("sql://%s?dsn=%s", engine, tmp_path)
-	slog.Debug(db_uri)
+	return tmp_path, nil
+}
+
+func testSQLDatabaseEngineAdaptiveGeohashes(ctx context.Context, engine string) error {
+
+	tmp_path, err := tempDatabasePath(engine)
+
+	if err != nil {
+		return err
+	}
+
+	db_uri := fmt.Sprintf("sql://%s?dsn=%s&max-block-size=2", engine, tmp_path)
 
 	db, err := NewDatabase(ctx, db_uri)
 
@@ -44,33 +256,196 @@ func testSQLDatabaseEngine(ctx context.Context, engine string) error {
 		return fmt.Errorf("Failed to create new database for %s, %v", db_uri, err)
 	}
 
-	pt := orb.Point([]float64{-73.60033, 45.524115})
+	defer db.Close(ctx)
+
+	points := []orb.Point{
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.60033, 45.524115},
+		orb.Point{-73.5987, 45.5231},
+		orb.Point{-122.4194, 37.7749},
+	}
+
+	for i, pt := range points {
+
+		loc := &Location{
+			ID:       fmt.Sprintf("%d", i),
+			Name:     fmt.Sprintf("Location %d", i),
+			Centroid: &pt,
+		}
+
+		err := db.AddLocation(ctx, loc)
+
+		if err != nil {
+			return fmt.Errorf("Failed to add location %d, %w", i, err)
+		}
+	}
+
+	geohashes := make([]string, 0)
+
+	err = db.GetGeohashes(ctx, func(ctx context.Context, geohash string) error {
+		geohashes = append(geohashes, geohash)
+		return nil
+	})
+
+	if err != nil {
+		return fmt.Errorf("Failed to get geohashes, %w", err)
+	}
+
+	total := 0
+
+	for _, geohash := range geohashes {
+
+		count := 0
+
+		err := db.GetWithGeohash(ctx, geohash, func(ctx context.Context, loc *Location) error {
+			count += 1
+			return nil
+		})
+
+		if err != nil {
+			return fmt.Errorf("Failed to get locations with geohash %s, %w", geohash, err)
+		}
+
+		if count > 2 && uint(len(geohash)) < MAX_GEOHASH_PRECISION {
+			return fmt.Errorf("Geohash %s exceeds maximum block size (%d)", geohash, count)
+		}
+
+		total += count
+	}
+
+	if total != len(points) {
+		return fmt.Errorf("Expected %d locations across all geohashes, got %d (%v)", len(points), total, geohashes)
+	}
+
+	if !slices.Contains(geohashes, "9q8y") {
+		return fmt.Errorf("Expected sparse geohash to be merged in to a coarser geohash, %v", geohashes)
+	}
+
+	return nil
+}
+
+// testSQLDatabaseEnginePreserveSources ensures that locations indexed with their source features and later re-added
+// without them, by a database opened without the ?store-source= parameter (as the enrich-locations tool does), keep their sources.
+func testSQLDatabaseEnginePreserveSources(ctx context.Context, engine string) error {
+
+	tmp_path, err := tempDatabasePath(engine)
+
+	if err != nil {
+		return err
+	}
+
+	defer os.Remove(tmp_path)
+
+	pt := orb.Point{-73.60033, 45.524115}
 
 	loc := &Location{
 		ID:       "1",
 		Name:     "Open Da Night",
-		Address:  "124 rue St. Viateur o. Montreal",
 		Centroid: &pt,
+		Feature:  []byte(`{"type":"Feature","properties":{"name":"Open Da Night"}}`),
 	}
 
-	err = db.AddLocation(ctx, loc)
+	index_uri := fmt.Sprintf("sql://%s?dsn=%s&store-source=true", engine, tmp_path)
+
+	index_db, err := NewDatabase(ctx, index_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new database for %s, %v", index_uri, err)
+	}
+
+	err = index_db.AddLocation(ctx, loc)
 
 	if err != nil {
 		return fmt.Errorf("Failed to add location, %w", err)
 	}
 
-	loc, err = db.GetById(ctx, "1")
+	err = index_db.Close(ctx)
+
+	if err != nil {
+		return fmt.Errorf("Failed to close database for %s, %v", index_uri, err)
+	}
+
+	enrich_uri := fmt.Sprintf("sql://%s?dsn=%s", engine, tmp_path)
+
+	enrich_db, err := NewDatabase(ctx, enrich_uri)
+
+	if err != nil {
+		return fmt.Errorf("Failed to create new database for %s, %v", enrich_uri, err)
+	}
+
+	defer enrich_db.Close(ctx)
+
+	loc2, err := enrich_db.GetById(ctx, "1")
 
 	if err != nil {
 		return fmt.Errorf("Failed to retrieve location, %w", err)
 	}
 
-	// To do: GetByGeohash, etc.
+	// Locations retrieved from the database do not have a Feature property so this is the same as
+	// re-adding (or re-importing) a location without its source feature.
 
-	err = db.Close(ctx)
+	loc2.Hierarchy = &Hierarchy{
+		ParentId:   101736545,
+		LocalityId: 101736545,
+	}
+
+	err = enrich_db.(BatchDatabase).AddLocations(ctx, []*Location{loc2})
 
 	if err != nil {
-		return fmt.Errorf("Failed to close database for %s, %v", db_uri, err)
+		return fmt.Errorf("Failed to update location, %w", err)
+	}
+
+	err = enrich_db.AddLocation(ctx, loc2)
+
+	if err != nil {
+		return fmt.Errorf("Failed to re-add location, %w", err)
+	}
+
+	source, err := enrich_db.GetSourceById(ctx, "1")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve source after update, %w", err)
+	}
+
+	if string(source) != string(loc.Feature) {
+		return fmt.Errorf("Unexpected source for updated location: %s", string(source))
+	}
+
+	loc3, err := enrich_db.GetById(ctx, "1")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve updated location, %w", err)
+	}
+
+	if loc3.Hierarchy == nil || loc3.Hierarchy.LocalityId != 101736545 {
+		return fmt.Errorf("Updated location is missing its hierarchy")
+	}
+
+	// Adding a location with a (new) feature replaces its stored source
+
+	loc3.Feature = []byte(`{"type":"Feature","properties":{"name":"Open Da Night!"}}`)
+
+	err = enrich_db.AddLocation(ctx, loc3)
+
+	if err != nil {
+		return fmt.Errorf("Failed to replace location, %w", err)
+	}
+
+	source, err = enrich_db.GetSourceById(ctx, "1")
+
+	if err != nil {
+		return fmt.Errorf("Failed to retrieve source after replacement, %w", err)
+	}
+
+	if string(source) != string(loc3.Feature) {
+		return fmt.Errorf("Unexpected source for replaced location: %s", string(source))
+	}
+
+	_, err = enrich_db.GetSourceById(ctx, "2")
+
+	if !dedupe.IsNotFoundError(err) {
+		return fmt.Errorf("Expected not found error for missing location, got %v", err)
 	}
 
 	return nil
@@ -0,0 +1,57 @@
+package location
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/whosonfirst/go-dedupe/embeddings"
+)
+
+// NewTextTemplate returns a new `text/template.Template` instance derived from 'str' for use with the
+// `Location.Text` method. Templates are evaluated against a `Location` instance so, for example, the
+// template "{{.Name}} | {{.Address}} | {{.Custom.category}}" will yield the location's name, address and
+// "category" metadata property separated by pipes. Missing custom metadata properties yield empty strings.
+func NewTextTemplate(str string) (*template.Template, error) {
+
+	t, err := template.New("text").Option("missingkey=zero").Parse(str)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse text template, %w", err)
+	}
+
+	return t, nil
+}
+
+// Text returns the text representation of the location derived from 't'. If 't' is nil then the
+// value of `String()` is returned.
+func (loc *Location) Text(t *template.Template) (string, error) {
+
+	if t == nil {
+		return loc.String(), nil
+	}
+
+	var buf strings.Builder
+
+	err := t.Execute(&buf, loc)
+
+	if err != nil {
+		return "", fmt.Errorf("Failed to execute text template for %s, %w", loc.ID, err)
+	}
+
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// Embeddings32WithTemplate derives vector embeddings for the text representation of the location produced
+// by 't' using 'embedder'. If 't' is nil then embeddings are derived from the value of `String()`.
+func (loc *Location) Embeddings32WithTemplate(ctx context.Context, embedder embeddings.Embedder, t *template.Template) ([]float32, error) {
+
+	text, err := loc.Text(t)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return embedder.Embeddings32(ctx, text)
+}
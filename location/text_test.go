@@ -0,0 +1,51 @@
+package location
+
+import (
+	"testing"
+)
+
+func TestLocationText(t *testing.T) {
+
+	loc := &Location{
+		ID:      "example:id=1",
+		Name:    "Matteo's Cafe",
+		Address: "123 Main St",
+		Custom: map[string]string{
+			"category": "cafe",
+		},
+	}
+
+	tests := map[string]string{
+		"":          "Matteo's Cafe, 123 Main St",
+		"{{.Name}}": "Matteo's Cafe",
+		"{{.Name}} | {{.Address}} | {{.Custom.category}}": "Matteo's Cafe | 123 Main St | cafe",
+		"{{.Name}} {{.Custom.missing}}":                   "Matteo's Cafe",
+	}
+
+	for str, expected := range tests {
+
+		var text string
+		var err error
+
+		if str == "" {
+			text, err = loc.Text(nil)
+		} else {
+
+			tmpl, tmpl_err := NewTextTemplate(str)
+
+			if tmpl_err != nil {
+				t.Fatalf("Failed to create template for '%s', %v", str, tmpl_err)
+			}
+
+			text, err = loc.Text(tmpl)
+		}
+
+		if err != nil {
+			t.Fatalf("Failed to derive text for '%s', %v", str, err)
+		}
+
+		if text != expected {
+			t.Fatalf("Unexpected text for '%s'. Expected '%s' but got '%s'", str, expected, text)
+		}
+	}
+}
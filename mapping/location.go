@@ -0,0 +1,195 @@
+package mapping
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/category"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+// ValuesFunc returns the value, or values, of 'key' in a record. Keys which are empty or not present in the
+// record yield an empty list.
+type ValuesFunc func(key string) []string
+
+// NewLocation returns a new `location.Location` instance for a record whose properties are derived by calling 'values'
+// with the keys defined in 'm' and whose centroid is derived from 'geom'. Records without an ID, name, address
+// or geometry are reported as invalid records (see `dedupe.InvalidRecord`).
+func NewLocation(m *Mapping, values ValuesFunc, geom orb.Geometry, release string) (*location.Location, error) {
+
+	value := func(key string) string {
+
+		v := values(key)
+
+		if len(v) == 0 {
+			return ""
+		}
+
+		return strings.TrimSpace(v[0])
+	}
+
+	id := value(m.ID)
+
+	if id == "" {
+		return nil, dedupe.InvalidRecord("#", fmt.Errorf("Missing '%s' property", m.ID))
+	}
+
+	name := value(m.Name)
+
+	if name == "" {
+		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing '%s' property", m.Name))
+	}
+
+	country := value(m.Country)
+
+	if country == "" {
+		country = m.DefaultCountry
+	}
+
+	components := &location.AddressComponents{
+		HouseNumber: value(m.HouseNumber),
+		Street:      value(m.Street),
+		Unit:        value(m.Unit),
+		Locality:    value(m.Locality),
+		Region:      value(m.Region),
+		Postcode:    value(m.Postcode),
+		Country:     country,
+	}
+
+	addr_components := make([]string, 0)
+
+	if len(m.Address) > 0 {
+
+		for _, k := range m.Address {
+
+			v := value(k)
+
+			if v != "" {
+				addr_components = append(addr_components, v)
+			}
+		}
+
+	} else {
+
+		for _, v := range []string{
+			strings.TrimSpace(fmt.Sprintf("%s %s", components.HouseNumber, components.Street)),
+			components.Unit,
+			components.Locality,
+			components.Region,
+			components.Postcode,
+		} {
+
+			if v != "" {
+				addr_components = append(addr_components, v)
+			}
+		}
+	}
+
+	if len(addr_components) == 0 {
+		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing address properties"))
+	}
+
+	addr := strings.Join(addr_components, " ")
+
+	components.ParseStreet()
+
+	if geom == nil {
+		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing geometry"))
+	}
+
+	f := geojson.NewFeature(geom)
+	centroid := f.Point()
+
+	c := &location.Location{
+		ID:                dedupe.NewID(m.Prefix, id).String(),
+		Name:              name,
+		Address:           addr,
+		Centroid:          &centroid,
+		AddressComponents: components,
+		Category:          category.Normalize(value(m.Category)),
+		Provenance: &location.Provenance{
+			Source:  m.Source,
+			Dataset: value(m.Dataset),
+			Release: release,
+		},
+	}
+
+	// Unparseable timestamps and confidence values are ignored rather than invalidating the record
+
+	str_lastmod := value(m.LastModified)
+
+	if str_lastmod != "" {
+
+		lastmod, err := strconv.ParseInt(str_lastmod, 10, 64)
+
+		if err != nil {
+
+			v, err := strconv.ParseFloat(str_lastmod, 64)
+
+			if err == nil {
+				lastmod = int64(v)
+			}
+		}
+
+		c.Provenance.LastModified = lastmod
+	}
+
+	str_confidence := value(m.Confidence)
+
+	if str_confidence != "" {
+
+		confidence, err := strconv.ParseFloat(str_confidence, 64)
+
+		if err == nil {
+			c.Provenance.Confidence = confidence
+		}
+	}
+
+	for _, phone := range values(m.Phone) {
+		c.AddPhone(strings.TrimSpace(phone), country)
+	}
+
+	for _, website := range values(m.Website) {
+		c.AddWebsite(strings.TrimSpace(website))
+	}
+
+	for k, key := range m.Custom {
+
+		v := value(key)
+
+		if v == "" {
+			continue
+		}
+
+		if c.Custom == nil {
+			c.Custom = make(map[string]string)
+		}
+
+		c.Custom[k] = v
+	}
+
+	return c, nil
+}
+
+// FeatureGeometry returns the geometry of the GeoJSON feature 'body', or nil if the feature does not have a geometry.
+func FeatureGeometry(body []byte) (orb.Geometry, error) {
+
+	geom_rsp := gjson.GetBytes(body, "geometry")
+
+	if !geom_rsp.Exists() || geom_rsp.String() == "" {
+		return nil, nil
+	}
+
+	geom, err := geojson.UnmarshalGeometry([]byte(geom_rsp.Raw))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return geom.Geometry(), nil
+}
@@ -0,0 +1,191 @@
+// Package mapping provides a configurable mapping of keys to location properties, and a function for deriving
+// `location.Location` records from those keys, shared by the `csvmapped` and `jsonpath` parsers. What a key is
+// depends on the parser using the mapping; for example a CSV column name or a gjson path.
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+// Mapping defines the keys used to derive the properties of a `location.Location`.
+type Mapping struct {
+	// The ID prefix assigned to locations, for example "acme". It will be registered with `dedupe.RegisterIDPrefix`.
+	Prefix string `json:"prefix"`
+	// The key for the unique identifier of each record.
+	ID string `json:"id,omitempty"`
+	// The key for the name of each record.
+	Name string `json:"name"`
+	// Zero or more keys whose (non-empty) values are joined to form the address of each record. If empty
+	// the address is derived from the address component keys.
+	Address []string `json:"address,omitempty"`
+	// The key for the house number of each record.
+	HouseNumber string `json:"house_number,omitempty"`
+	// The key for the street (including the house number if there is no `HouseNumber` key) of each record.
+	Street string `json:"street,omitempty"`
+	// The key for the unit of each record.
+	Unit string `json:"unit,omitempty"`
+	// The key for the city or town of each record.
+	Locality string `json:"locality,omitempty"`
+	// The key for the state or province of each record.
+	Region string `json:"region,omitempty"`
+	// The key for the postal code of each record.
+	Postcode string `json:"postcode,omitempty"`
+	// The key for the (ISO 3166-1 alpha-2) country code of each record.
+	Country string `json:"country,omitempty"`
+	// The country code to use when there is no `Country` key, or its value is empty.
+	DefaultCountry string `json:"default_country,omitempty"`
+	// The key for the category of each record.
+	Category string `json:"category,omitempty"`
+	// The key for the phone number, or phone numbers, of each record.
+	Phone string `json:"phone,omitempty"`
+	// The key for the website, or websites, of each record.
+	Website string `json:"website,omitempty"`
+	// The value assigned to the `Provenance.Source` property of each location. Default is the value of `Prefix`.
+	Source string `json:"source,omitempty"`
+	// The key for the value assigned to the `Provenance.Dataset` property of each location.
+	Dataset string `json:"dataset,omitempty"`
+	// The key for the (Unix timestamp) value assigned to the `Provenance.LastModified` property of each location.
+	LastModified string `json:"lastmodified,omitempty"`
+	// The key for the value assigned to the `Provenance.Confidence` property of each location.
+	Confidence string `json:"confidence,omitempty"`
+	// A dictionary of custom metadata keys and the (mapping) keys for their values, assigned to the `Custom` property of each location.
+	Custom map[string]string `json:"custom,omitempty"`
+}
+
+// NewMappingFromURI returns a new `Mapping` instance derived from the query parameters in 'u'. If there is
+// a `?mapping=` parameter the mapping is first read from the JSON file it points to and then any other parameters
+// are applied on top of it as described in `ApplyQuery`.
+func NewMappingFromURI(u *url.URL) (*Mapping, error) {
+
+	q := u.Query()
+
+	m := &Mapping{}
+
+	if q.Has("mapping") {
+
+		err := ReadMappingFile(q.Get("mapping"), m)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := m.ApplyQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ReadMappingFile decodes the JSON-encoded mapping file 'path' in to 'm' which is expected to be a pointer to
+// a `Mapping` instance or to a struct which embeds one. Unknown properties are an error.
+func ReadMappingFile(path string, m any) error {
+
+	r, err := os.Open(path)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open mapping file %s, %w", path, err)
+	}
+
+	defer r.Close()
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+
+	err = dec.Decode(m)
+
+	if err != nil {
+		return fmt.Errorf("Failed to decode mapping file %s, %w", path, err)
+	}
+
+	return nil
+}
+
+// ApplyQuery assigns the values of any mapping parameters in 'q', named after the mapping's JSON keys with hyphens
+// instead of underscores, to 'm'. Address keys may be passed multiple times and custom metadata keys are passed as
+// one or more `?custom={KEY}:{MAPPING_KEY}` parameters. If `Source` is empty after the parameters have been applied
+// it is assigned the value of `Prefix`.
+func (m *Mapping) ApplyQuery(q url.Values) error {
+
+	params := map[string]*string{
+		"prefix":          &m.Prefix,
+		"id":              &m.ID,
+		"name":            &m.Name,
+		"house-number":    &m.HouseNumber,
+		"street":          &m.Street,
+		"unit":            &m.Unit,
+		"locality":        &m.Locality,
+		"region":          &m.Region,
+		"postcode":        &m.Postcode,
+		"country":         &m.Country,
+		"default-country": &m.DefaultCountry,
+		"category":        &m.Category,
+		"phone":           &m.Phone,
+		"website":         &m.Website,
+		"source":          &m.Source,
+		"dataset":         &m.Dataset,
+		"lastmodified":    &m.LastModified,
+		"confidence":      &m.Confidence,
+	}
+
+	for k, v := range params {
+
+		if q.Has(k) {
+			*v = q.Get(k)
+		}
+	}
+
+	if q.Has("address") {
+		m.Address = q["address"]
+	}
+
+	for _, v := range q["custom"] {
+
+		k, key, ok := strings.Cut(v, ":")
+
+		if !ok || k == "" || key == "" {
+			return fmt.Errorf("Invalid ?custom= parameter, expected {KEY}:{MAPPING_KEY}")
+		}
+
+		if m.Custom == nil {
+			m.Custom = make(map[string]string)
+		}
+
+		m.Custom[k] = key
+	}
+
+	if m.Source == "" {
+		m.Source = m.Prefix
+	}
+
+	return nil
+}
+
+// Validate ensures that 'm' defines a prefix and a name key and that none of its custom metadata keys are reserved.
+func (m *Mapping) Validate() error {
+
+	if m.Prefix == "" {
+		return fmt.Errorf("Missing prefix")
+	}
+
+	if m.Name == "" {
+		return fmt.Errorf("Missing name key")
+	}
+
+	for k, _ := range m.Custom {
+
+		if location.IsReservedMetadataKey(k) {
+			return fmt.Errorf("Custom metadata key '%s' is reserved", k)
+		}
+	}
+
+	return nil
+}
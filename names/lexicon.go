@@ -0,0 +1,78 @@
+package names
+
+// The lookup tables in this file are not meant to be exhaustive. They cover the suffixes and
+// abbreviations most commonly encountered in the data sources supported by this package.
+
+// legal_suffixes is the list of (lower-cased, punctuation-free) legal and corporate suffixes which
+// are removed from the end of names.
+var legal_suffixes = map[string]bool{
+	"ag":           true,
+	"bv":           true,
+	"co":           true,
+	"company":      true,
+	"corp":         true,
+	"corporation":  true,
+	"gmbh":         true,
+	"inc":          true,
+	"incorporated": true,
+	"limited":      true,
+	"llc":          true,
+	"llp":          true,
+	"lp":           true,
+	"ltd":          true,
+	"nv":           true,
+	"pc":           true,
+	"plc":          true,
+	"pllc":         true,
+	"pty":          true,
+	"sa":           true,
+	"sarl":         true,
+	"sas":          true,
+	"srl":          true,
+}
+
+// abbreviations maps (lower-cased, punctuation-free) abbreviations to their expanded form.
+var abbreviations = map[string]string{
+	"acctnt":  "accountant",
+	"assn":    "association",
+	"assoc":   "associates",
+	"bros":    "brothers",
+	"ctr":     "center",
+	"centre":  "center",
+	"dept":    "department",
+	"ft":      "fort",
+	"hosp":    "hospital",
+	"intl":    "international",
+	"mfg":     "manufacturing",
+	"mkt":     "market",
+	"mt":      "mount",
+	"natl":    "national",
+	"restrnt": "restaurant",
+	"rstrnt":  "restaurant",
+	"st":      "saint",
+	"ste":     "sainte",
+	"svc":     "service",
+	"svcs":    "services",
+	"univ":    "university",
+}
+
+// ligatures maps letters which are not decomposed by Unicode normalization to their ASCII equivalents.
+var ligatures = map[rune]string{
+	'ß': "ss",
+	'æ': "ae",
+	'Æ': "ae",
+	'œ': "oe",
+	'Œ': "oe",
+	'ø': "o",
+	'Ø': "o",
+	'ł': "l",
+	'Ł': "l",
+	'đ': "d",
+	'Đ': "d",
+	'ı': "i",
+}
+
+func isLegalSuffix(w string) bool {
+	_, exists := legal_suffixes[w]
+	return exists
+}
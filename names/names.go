@@ -0,0 +1,103 @@
+// Package names provides methods for normalizing the names of venues (places) by folding diacritics,
+// unifying "&" and "and", removing legal and corporate suffixes (Inc, LLC, PC, Corp, Ltd, GmbH, SA) and
+// expanding common abbreviations. It is meant to resolve trivial differences between names before (or
+// instead of) deriving vector embeddings.
+package names
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// re_initialism matches dotted initialisms like "P.C." or "U.S.A."
+var re_initialism = regexp.MustCompile(`(?:^|\b)(?:\pL\.){2,}`)
+
+// Normalize returns a lower-cased version of 'name' with diacritics folded, punctuation removed, "&"
+// replaced by "and", legal and corporate suffixes removed and common abbreviations expanded. For example
+// "T&T Pest Control, Inc." and "T and T Pest Control" both become "t and t pest control".
+func Normalize(name string) string {
+
+	words := strings.Fields(fold(name))
+
+	// Remove legal and corporate suffixes, which may be chained ("Foo Co Ltd")
+
+	for len(words) > 1 {
+
+		last := words[len(words)-1]
+
+		if !isLegalSuffix(last) {
+			break
+		}
+
+		words = words[0 : len(words)-1]
+
+		// "Foo and Co"
+
+		if len(words) > 1 && words[len(words)-1] == "and" {
+			words = words[0 : len(words)-1]
+		}
+	}
+
+	// Remove leading articles
+
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+
+	for i, w := range words {
+
+		v, exists := abbreviations[w]
+
+		if exists {
+			words[i] = v
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// Key returns a compact version of 'name', suitable for use as a key for exact matching. It is the
+// value of `Normalize(name)` with all whitespace removed.
+func Key(name string) string {
+	return strings.Join(strings.Fields(Normalize(name)), "")
+}
+
+// fold returns a lower-cased version of 's' with diacritics removed, "&" (and "+") replaced by " and "
+// and all punctuation other than apostrophes replaced by whitespace. Apostrophes are removed entirely
+// so that "Matteo's" and "Matteos" yield the same value.
+func fold(s string) string {
+
+	s = re_initialism.ReplaceAllStringFunc(s, func(m string) string {
+		return strings.ReplaceAll(m, ".", "")
+	})
+
+	var b strings.Builder
+
+	for _, r := range norm.NFD.String(s) {
+
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+
+		if v, exists := ligatures[r]; exists {
+			b.WriteString(v)
+			continue
+		}
+
+		switch {
+		case r == '&' || r == '+':
+			b.WriteString(" and ")
+		case r == '\'' || r == '’' || r == '`':
+			// pass
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	return b.String()
+}
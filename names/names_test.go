@@ -0,0 +1,49 @@
+package names
+
+import (
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+
+	tests := map[string]string{
+		"T&T Pest Control":             "t and t pest control",
+		"T and T Pest Control, Inc.":   "t and t pest control",
+		"Prosthodontic Associates PC":  "prosthodontic associates",
+		"Hudson Shipping Lines Corp.":  "hudson shipping lines",
+		"Café Olimpico":                "cafe olimpico",
+		"Montréal Pâtisserie GmbH":     "montreal patisserie",
+		"La Villa Pizzeria & Restrnt":  "la villa pizzeria and restaurant",
+		"Matteo's Cafe":                "matteos cafe",
+		"The Pisciotta Capital Co Ltd": "pisciotta capital",
+		"Inc":                          "inc",
+	}
+
+	for name, expected := range tests {
+
+		v := Normalize(name)
+
+		if v != expected {
+			t.Fatalf("Unexpected value normalizing '%s': '%s' (expected '%s')", name, v, expected)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+
+	same := [][]string{
+		[]string{"Gray Cpa Pc", "GRAY CPA, P.C."},
+		[]string{"Matteo's Cafe", "Matteos Café"},
+		[]string{"T&T Pest Control", "T and T Pest Control"},
+	}
+
+	for _, pair := range same {
+
+		a := Key(pair[0])
+		b := Key(pair[1])
+
+		if a != b {
+			t.Fatalf("Expected '%s' and '%s' to have the same key, got '%s' and '%s'", pair[0], pair[1], a, b)
+		}
+	}
+}
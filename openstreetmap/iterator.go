@@ -0,0 +1,297 @@
+package openstreetmap
+
+// > go run cmd/index-locations/main.go -verbose -location-database-uri null:// -location-parser-uri openstreetmap:// -iterator-uri osm:// /usr/local/data/osm/quebec-latest.osm.pbf
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+	"github.com/whosonfirst/go-dedupe/iterator"
+)
+
+type OpenStreetMapIterator struct {
+	iterator.Iterator
+	max_workers int
+	poi_keys    []string
+}
+
+func init() {
+	ctx := context.Background()
+	err := iterator.RegisterIterator(ctx, "osm", NewOpenStreetMapIterator)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewOpenStreetMapIterator returns a new `OpenStreetMapIterator` instance configured by 'uri' which is expected to take the form of:
+//
+//	osm://?{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?max-workers=` The maximum number of callbacks to invoke concurrently. Default is 20.
+// * `?poi-keys=` A comma-separated list of tags, one of which an element must have (in addition to a "name" tag) in order to be emitted. Default is "amenity,shop,tourism,office".
+func NewOpenStreetMapIterator(ctx context.Context, uri string) (iterator.Iterator, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	max_workers := 20
+
+	if q.Has("max-workers") {
+
+		v, err := strconv.Atoi(q.Get("max-workers"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?max-workers= parameter, %w", err)
+		}
+
+		max_workers = v
+	}
+
+	poi_keys := default_poi_keys
+
+	if q.Has("poi-keys") {
+		poi_keys = strings.Split(q.Get("poi-keys"), ",")
+	}
+
+	iter := &OpenStreetMapIterator{
+		max_workers: max_workers,
+		poi_keys:    poi_keys,
+	}
+
+	return iter, nil
+}
+
+// IterateWithCallback emits the named points of interest (nodes and the centroids of ways) in one or more OSM PBF files
+// as GeoJSON features. Each file is read twice: once to find the ways which are points of interest and the nodes they
+// reference, and then again to find the coordinates for those nodes and the nodes which are points of interest themselves.
+// Features are passed to 'cb' concurrently (up to ?max-workers= at a time) and any errors returned by 'cb' are logged
+// rather than returned; only errors reading the files themselves, or the context being cancelled, are returned. Only raw
+// and zlib-compressed blocks are supported; files containing lzma, bzip2, lz4 or zstd-compressed blocks are an error.
+func (iter *OpenStreetMapIterator) IterateWithCallback(ctx context.Context, cb iterator.IteratorCallback, uris ...string) error {
+
+	throttle := make(chan bool, iter.max_workers)
+
+	for i := 0; i < iter.max_workers; i++ {
+		throttle <- true
+	}
+
+	wg := new(sync.WaitGroup)
+
+	// Wait for any callbacks which have already been started even if reading a file fails or is cancelled
+
+	defer wg.Wait()
+
+	emit := func(logger *slog.Logger, el *element, pt orb.Point) {
+
+		body, err := iter.feature(el, pt)
+
+		if err != nil {
+			logger.Error("Failed to marshal record", "type", el.Type, "id", el.Id, "error", err)
+			return
+		}
+
+		<-throttle
+
+		wg.Add(1)
+
+		go func() {
+
+			defer func() {
+				wg.Done()
+				throttle <- true
+			}()
+
+			err := cb(ctx, body)
+
+			if err != nil {
+				logger.Error("Callback failed for record", "type", el.Type, "id", el.Id, "error", err)
+			}
+		}()
+	}
+
+	for _, path := range uris {
+
+		logger := slog.Default()
+		logger = logger.With("path", path)
+
+		// First pass: ways
+
+		ways := make([]*element, 0)
+		refs := make(map[int64]bool)
+
+		ways_cb := func(el *element) error {
+
+			if !iter.isPointOfInterest(el) {
+				return nil
+			}
+
+			for _, id := range el.Refs {
+				refs[id] = true
+			}
+
+			ways = append(ways, el)
+			return nil
+		}
+
+		logger.Debug("Read ways")
+
+		err := iter.readFile(ctx, path, &readPBFOptions{Ways: true}, ways_cb)
+
+		if err != nil {
+			return err
+		}
+
+		// Second pass: nodes
+
+		coords := make(map[int64]orb.Point)
+
+		nodes_cb := func(el *element) error {
+
+			pt := orb.Point{el.Lon, el.Lat}
+
+			if refs[el.Id] {
+				coords[el.Id] = pt
+			}
+
+			if iter.isPointOfInterest(el) {
+				emit(logger, el, pt)
+			}
+
+			return nil
+		}
+
+		logger.Debug("Read nodes", "ways", len(ways), "refs", len(refs))
+
+		err = iter.readFile(ctx, path, &readPBFOptions{Nodes: true}, nodes_cb)
+
+		if err != nil {
+			return err
+		}
+
+		for _, el := range ways {
+
+			pt, ok := wayCentroid(el, coords)
+
+			if !ok {
+				logger.Debug("Unable to derive centroid for way, skipping", "id", el.Id)
+				continue
+			}
+
+			emit(logger, el, pt)
+		}
+	}
+
+	return nil
+}
+
+func (iter *OpenStreetMapIterator) Close(ctx context.Context) error {
+	return nil
+}
+
+// readFile opens 'path' and invokes 'cb' for each of the elements enabled by 'opts'.
+func (iter *OpenStreetMapIterator) readFile(ctx context.Context, path string, opts *readPBFOptions, cb func(*element) error) error {
+
+	r, err := os.Open(path)
+
+	if err != nil {
+		return fmt.Errorf("Failed to open %s for reading, %w", path, err)
+	}
+
+	defer r.Close()
+
+	err = readPBF(ctx, r, opts, cb)
+
+	if err != nil {
+		return fmt.Errorf("Failed to read %s, %w", path, err)
+	}
+
+	return nil
+}
+
+// isPointOfInterest returns a boolean value indicating whether 'el' has a "name" tag and one of the iterator's
+// point of interest tags.
+func (iter *OpenStreetMapIterator) isPointOfInterest(el *element) bool {
+
+	if el.Tags == nil || el.Tags["name"] == "" {
+		return false
+	}
+
+	for _, k := range iter.poi_keys {
+
+		if el.Tags[k] != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// feature returns a JSON-encoded GeoJSON Feature for 'el' located at 'pt'. The feature's properties are the element's tags
+// and its ID, type and last modified timestamp which are assigned to the "@id", "@type" and "@timestamp" properties.
+func (iter *OpenStreetMapIterator) feature(el *element, pt orb.Point) ([]byte, error) {
+
+	f := geojson.NewFeature(pt)
+	f.ID = fmt.Sprintf("%s/%d", el.Type, el.Id)
+
+	for k, v := range el.Tags {
+		f.Properties[k] = v
+	}
+
+	f.Properties["@id"] = el.Id
+	f.Properties["@type"] = el.Type
+
+	if el.Timestamp > 0 {
+		f.Properties["@timestamp"] = el.Timestamp
+	}
+
+	return f.MarshalJSON()
+}
+
+// wayCentroid returns the centroid of the way 'el' derived from the coordinates of its nodes in 'coords'. Closed
+// ways are treated as polygons and open ways as lines. Nodes which are not present in 'coords', for example because
+// they fall outside the boundaries of an extract, are ignored. If none of the nodes are present then false is returned.
+func wayCentroid(el *element, coords map[int64]orb.Point) (orb.Point, bool) {
+
+	ls := make(orb.LineString, 0)
+
+	for _, id := range el.Refs {
+
+		pt, exists := coords[id]
+
+		if exists {
+			ls = append(ls, pt)
+		}
+	}
+
+	switch len(ls) {
+	case 0:
+		return orb.Point{}, false
+	case 1:
+		return ls[0], true
+	}
+
+	var centroid orb.Point
+
+	if len(ls) >= 4 && orb.Ring(ls).Closed() {
+		centroid, _ = planar.CentroidArea(orb.Polygon{orb.Ring(ls)})
+	} else {
+		centroid, _ = planar.CentroidArea(ls)
+	}
+
+	return centroid, true
+}
@@ -1,3 +1,13 @@
 package openstreetmap
 
-// To do...
+// https://wiki.openstreetmap.org/wiki/Map_features
+// https://wiki.openstreetmap.org/wiki/Key:addr:*
+
+// default_poi_keys are the tags, one of which an element must have (in addition to a "name" tag), in order to be
+// considered a point of interest by the `OpenStreetMapIterator`.
+var default_poi_keys = []string{
+	"amenity",
+	"shop",
+	"tourism",
+	"office",
+}
@@ -0,0 +1,326 @@
+package openstreetmap
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/whosonfirst/go-dedupe/iterator"
+	"github.com/whosonfirst/go-dedupe/location"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestOpenStreetMap(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "osm")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	path := filepath.Join(tmp_dir, "test.osm.pbf")
+
+	err = os.WriteFile(path, testPBF(t), 0644)
+
+	if err != nil {
+		t.Fatalf("Failed to write PBF file, %v", err)
+	}
+
+	iter, err := iterator.NewIterator(ctx, "osm://")
+
+	if err != nil {
+		t.Fatalf("Failed to create iterator, %v", err)
+	}
+
+	defer iter.Close(ctx)
+
+	prsr, err := location.NewParser(ctx, "openstreetmap://")
+
+	if err != nil {
+		t.Fatalf("Failed to create parser, %v", err)
+	}
+
+	locs := make(map[string]*location.Location)
+	mu := new(sync.Mutex)
+
+	iter_cb := func(ctx context.Context, body []byte) error {
+
+		loc, err := prsr.Parse(ctx, body)
+
+		if err != nil {
+			return err
+		}
+
+		mu.Lock()
+		locs[loc.ID] = loc
+		mu.Unlock()
+
+		return nil
+	}
+
+	err = iter.IterateWithCallback(ctx, iter_cb, path)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate, %v", err)
+	}
+
+	if len(locs) != 2 {
+		t.Fatalf("Expected 2 locations, got %d", len(locs))
+	}
+
+	cafe, exists := locs["osm:node=1"]
+
+	if !exists {
+		t.Fatalf("Missing osm:node=1")
+	}
+
+	if cafe.Name != "Cafe Olimpico" || cafe.Address != "124 Rue Saint-Viateur O Montréal" {
+		t.Fatalf("Unexpected location: %s", cafe.String())
+	}
+
+	if cafe.Centroid.Lon() < -73.6001 || cafe.Centroid.Lon() > -73.6000 || cafe.Centroid.Lat() < 45.5240 || cafe.Centroid.Lat() > 45.5241 {
+		t.Fatalf("Unexpected centroid: %v", cafe.Centroid)
+	}
+
+	if len(cafe.Phones) != 1 || cafe.Phones[0] != "+15144950746" {
+		t.Fatalf("Unexpected phones: %v", cafe.Phones)
+	}
+
+	if cafe.Provenance.LastModified != 1700000000 {
+		t.Fatalf("Unexpected last modified: %d", cafe.Provenance.LastModified)
+	}
+
+	shop, exists := locs["osm:way=10"]
+
+	if !exists {
+		t.Fatalf("Missing osm:way=10")
+	}
+
+	// The centroid of the square formed by nodes 2-5
+
+	if shop.Centroid.Lon() < -73.5951 || shop.Centroid.Lon() > -73.5949 || shop.Centroid.Lat() < 45.5249 || shop.Centroid.Lat() > 45.5251 {
+		t.Fatalf("Unexpected centroid: %v", shop.Centroid)
+	}
+}
+
+func TestUnsupportedCompression(t *testing.T) {
+
+	ctx := context.Background()
+
+	// A zstd-compressed (field 7) blob. The data itself is never decompressed so it doesn't need to be valid.
+
+	var blob []byte
+	blob = protowire.AppendTag(blob, 2, protowire.VarintType)
+	blob = protowire.AppendVarint(blob, 4)
+	blob = appendBytes(blob, 7, []byte("zstd"))
+
+	var header []byte
+	header = protowire.AppendTag(header, 1, protowire.BytesType)
+	header = protowire.AppendString(header, "OSMData")
+	header = protowire.AppendTag(header, 3, protowire.VarintType)
+	header = protowire.AppendVarint(header, uint64(len(blob)))
+
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint32(len(header)))
+	buf.Write(header)
+	buf.Write(blob)
+
+	cb := func(el *element) error {
+		return nil
+	}
+
+	err := readPBF(ctx, &buf, &readPBFOptions{Nodes: true, Ways: true}, cb)
+
+	if !errors.Is(err, unsupportedCompressionError) {
+		t.Fatalf("Expected unsupported compression error, got %v", err)
+	}
+}
+
+// testPBF returns a minimal OSM PBF file containing a named cafe (node 1), an unnamed bench (node 6) and a named
+// shop (way 10) whose nodes (2-5) form a square. Nodes are dense-encoded in a zlib-compressed block and the way is
+// encoded in an uncompressed block.
+func testPBF(t *testing.T) []byte {
+
+	strings := []string{
+		"",
+		"name", "Cafe Olimpico",
+		"amenity", "cafe",
+		"addr:housenumber", "124",
+		"addr:street", "Rue Saint-Viateur O",
+		"addr:city", "Montréal",
+		"phone", "+1 514 495 0746",
+		"shop", "bakery",
+		"Boulangerie",
+		"bench",
+	}
+
+	idx := func(s string) uint64 {
+
+		for i, v := range strings {
+			if v == s {
+				return uint64(i)
+			}
+		}
+
+		t.Fatalf("Invalid string %s", s)
+		return 0
+	}
+
+	var stringtable []byte
+
+	for _, s := range strings {
+		stringtable = protowire.AppendTag(stringtable, 1, protowire.BytesType)
+		stringtable = protowire.AppendString(stringtable, s)
+	}
+
+	// Coordinates are encoded with the default granularity of 100 nanodegrees
+
+	type node struct {
+		id   int64
+		lat  float64
+		lon  float64
+		tags []string
+	}
+
+	nodes := []node{
+		{1, 45.524050, -73.600050, []string{"name", "Cafe Olimpico", "amenity", "cafe", "addr:housenumber", "124", "addr:street", "Rue Saint-Viateur O", "addr:city", "Montréal", "phone", "+1 514 495 0746"}},
+		{2, 45.524900, -73.595100, nil},
+		{3, 45.524900, -73.594900, nil},
+		{4, 45.525100, -73.594900, nil},
+		{5, 45.525100, -73.595100, nil},
+		{6, 45.524000, -73.600000, []string{"amenity", "bench"}},
+	}
+
+	var ids, lats, lons, keys_vals, timestamps []byte
+	var last_id, last_lat, last_lon, last_ts int64
+
+	for _, n := range nodes {
+
+		lat := int64(n.lat * 1e7)
+		lon := int64(n.lon * 1e7)
+		ts := int64(1700000000)
+
+		ids = protowire.AppendVarint(ids, protowire.EncodeZigZag(n.id-last_id))
+		lats = protowire.AppendVarint(lats, protowire.EncodeZigZag(lat-last_lat))
+		lons = protowire.AppendVarint(lons, protowire.EncodeZigZag(lon-last_lon))
+		timestamps = protowire.AppendVarint(timestamps, protowire.EncodeZigZag(ts-last_ts))
+
+		last_id, last_lat, last_lon, last_ts = n.id, lat, lon, ts
+
+		for _, s := range n.tags {
+			keys_vals = protowire.AppendVarint(keys_vals, idx(s))
+		}
+
+		keys_vals = protowire.AppendVarint(keys_vals, 0)
+	}
+
+	var dense_info []byte
+	dense_info = appendBytes(dense_info, 2, timestamps)
+
+	var dense []byte
+	dense = appendBytes(dense, 1, ids)
+	dense = appendBytes(dense, 5, dense_info)
+	dense = appendBytes(dense, 8, lats)
+	dense = appendBytes(dense, 9, lons)
+	dense = appendBytes(dense, 10, keys_vals)
+
+	var nodes_group []byte
+	nodes_group = appendBytes(nodes_group, 2, dense)
+
+	var refs []byte
+	last_ref := int64(0)
+
+	for _, ref := range []int64{2, 3, 4, 5, 2} {
+		refs = protowire.AppendVarint(refs, protowire.EncodeZigZag(ref-last_ref))
+		last_ref = ref
+	}
+
+	way_tags := appendVarints(nil, idx("name"), idx("shop"), idx("addr:street"), idx("addr:city"))
+	way_vals := appendVarints(nil, idx("Boulangerie"), idx("bakery"), idx("Rue Saint-Viateur O"), idx("Montréal"))
+
+	var way []byte
+	way = protowire.AppendTag(way, 1, protowire.VarintType)
+	way = protowire.AppendVarint(way, 10)
+	way = appendBytes(way, 2, way_tags)
+	way = appendBytes(way, 3, way_vals)
+	way = appendBytes(way, 8, refs)
+
+	var ways_group []byte
+	ways_group = appendBytes(ways_group, 3, way)
+
+	var nodes_block []byte
+	nodes_block = appendBytes(nodes_block, 1, stringtable)
+	nodes_block = appendBytes(nodes_block, 2, nodes_group)
+
+	var ways_block []byte
+	ways_block = appendBytes(ways_block, 1, stringtable)
+	ways_block = appendBytes(ways_block, 2, ways_group)
+
+	var buf bytes.Buffer
+
+	writeBlob(t, &buf, "OSMHeader", nil, false)
+	writeBlob(t, &buf, "OSMData", ways_block, false)
+	writeBlob(t, &buf, "OSMData", nodes_block, true)
+
+	return buf.Bytes()
+}
+
+func writeBlob(t *testing.T, buf *bytes.Buffer, blob_type string, data []byte, compress bool) {
+
+	var blob []byte
+
+	if compress {
+
+		var zbuf bytes.Buffer
+		zw := zlib.NewWriter(&zbuf)
+
+		_, err := zw.Write(data)
+
+		if err != nil {
+			t.Fatalf("Failed to compress blob, %v", err)
+		}
+
+		zw.Close()
+
+		blob = protowire.AppendTag(blob, 2, protowire.VarintType)
+		blob = protowire.AppendVarint(blob, uint64(len(data)))
+		blob = appendBytes(blob, 3, zbuf.Bytes())
+
+	} else {
+		blob = appendBytes(blob, 1, data)
+	}
+
+	var header []byte
+	header = protowire.AppendTag(header, 1, protowire.BytesType)
+	header = protowire.AppendString(header, blob_type)
+	header = protowire.AppendTag(header, 3, protowire.VarintType)
+	header = protowire.AppendVarint(header, uint64(len(blob)))
+
+	binary.Write(buf, binary.BigEndian, uint32(len(header)))
+	buf.Write(header)
+	buf.Write(blob)
+}
+
+func appendBytes(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func appendVarints(b []byte, values ...uint64) []byte {
+
+	for _, v := range values {
+		b = protowire.AppendVarint(b, v)
+	}
+
+	return b
+}
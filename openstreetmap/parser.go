@@ -0,0 +1,244 @@
+package openstreetmap
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/paulmach/orb/geojson"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/category"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+type OpenStreetMapParser struct {
+	location.Parser
+	category_keys []string
+	release       string
+}
+
+func init() {
+	ctx := context.Background()
+	err := location.RegisterParser(ctx, "openstreetmap", NewOpenStreetMapParser)
+
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewOpenStreetMapParser returns a new `OpenStreetMapParser` instance for GeoJSON features produced by the `OpenStreetMapIterator`.
+func NewOpenStreetMapParser(ctx context.Context, uri string) (location.Parser, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	// The tags to derive a category from, in order of precedence
+	category_keys := []string{
+		"amenity",
+		"healthcare",
+		"shop",
+		"tourism",
+		"leisure",
+		"office",
+	}
+
+	p := &OpenStreetMapParser{
+		category_keys: category_keys,
+		release:       q.Get("release"),
+	}
+
+	return p, nil
+}
+
+func (p *OpenStreetMapParser) Parse(ctx context.Context, body []byte) (*location.Location, error) {
+
+	id_rsp := gjson.GetBytes(body, "properties.@id")
+	type_rsp := gjson.GetBytes(body, "properties.@type")
+
+	if !id_rsp.Exists() || !type_rsp.Exists() {
+		return nil, dedupe.InvalidRecord("#", fmt.Errorf("Missing '@id' or '@type' property"))
+	}
+
+	id := fmt.Sprintf("%s/%s", type_rsp.String(), id_rsp.String())
+
+	name := gjson.GetBytes(body, "properties.name").String()
+
+	if name == "" {
+		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing 'name' property"))
+	}
+
+	country := gjson.GetBytes(body, "properties.addr:country").String()
+
+	components := &location.AddressComponents{
+		HouseNumber: gjson.GetBytes(body, "properties.addr:housenumber").String(),
+		Street:      gjson.GetBytes(body, "properties.addr:street").String(),
+		Unit:        gjson.GetBytes(body, "properties.addr:unit").String(),
+		Locality:    firstTag(body, "addr:city", "addr:town", "addr:village", "addr:suburb"),
+		Region:      firstTag(body, "addr:state", "addr:province"),
+		Postcode:    gjson.GetBytes(body, "properties.addr:postcode").String(),
+		Country:     country,
+	}
+
+	addr := gjson.GetBytes(body, "properties.addr:full").String()
+
+	if addr == "" {
+
+		addr_components := make([]string, 0)
+
+		for _, v := range []string{
+			strings.TrimSpace(fmt.Sprintf("%s %s", components.HouseNumber, components.Street)),
+			components.Locality,
+			components.Region,
+			components.Postcode,
+		} {
+
+			if v != "" {
+				addr_components = append(addr_components, v)
+			}
+		}
+
+		addr = strings.Join(addr_components, " ")
+	}
+
+	if addr == "" {
+		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing 'addr:*' properties"))
+	}
+
+	components.ParseStreet()
+
+	geom_rsp := gjson.GetBytes(body, "geometry")
+
+	if !geom_rsp.Exists() || geom_rsp.String() == "" {
+		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing geometry"))
+	}
+
+	geom, err := geojson.UnmarshalGeometry([]byte(geom_rsp.Raw))
+
+	if err != nil {
+		return nil, err
+	}
+
+	f := geojson.NewFeature(geom.Geometry())
+	centroid := f.Point()
+
+	c := &location.Location{
+		ID:                dedupe.OpenStreetMapId(type_rsp.String(), id_rsp.String()),
+		Name:              name,
+		AlternateNames:    alternateNames(body),
+		Category:          p.category(body),
+		Address:           addr,
+		Centroid:          &centroid,
+		AddressComponents: components,
+		Provenance: &location.Provenance{
+			Source:       "openstreetmap",
+			Release:      p.release,
+			LastModified: gjson.GetBytes(body, "properties.@timestamp").Int(),
+		},
+	}
+
+	// OSM tags may contain multiple values separated by semi-colons
+
+	for _, k := range []string{"phone", "contact:phone"} {
+
+		for _, phone := range strings.Split(gjson.GetBytes(body, "properties."+k).String(), ";") {
+			c.AddPhone(phone, country)
+		}
+	}
+
+	for _, k := range []string{"website", "contact:website"} {
+
+		for _, website := range strings.Split(gjson.GetBytes(body, "properties."+k).String(), ";") {
+			c.AddWebsite(website)
+		}
+	}
+
+	return c, nil
+}
+
+// category returns the normalized category derived from the first of the parser's category keys that
+// yields a known category.
+func (p *OpenStreetMapParser) category(body []byte) string {
+
+	for _, k := range p.category_keys {
+
+		v := gjson.GetBytes(body, "properties."+k).String()
+
+		if v == "" {
+			continue
+		}
+
+		c := category.Normalize(v)
+
+		// Any shop=* tag is a shop, even if we don't recognize its value
+
+		if c == category.UNKNOWN && k == "shop" {
+			c = category.SHOPPING
+		}
+
+		if c != category.UNKNOWN {
+			return c
+		}
+	}
+
+	return category.UNKNOWN
+}
+
+// alternateNames returns the list of `location.AlternateName` instances derived from the "name:{LANG}" and
+// "alt_name" tags in 'body'.
+func alternateNames(body []byte) []*location.AlternateName {
+
+	alt_names := make([]*location.AlternateName, 0)
+
+	gjson.GetBytes(body, "properties").ForEach(func(k gjson.Result, v gjson.Result) bool {
+
+		lang, ok := strings.CutPrefix(k.String(), "name:")
+
+		if ok && lang != "" && v.String() != "" {
+
+			alt_names = append(alt_names, &location.AlternateName{
+				Name:     v.String(),
+				Language: lang,
+				Kind:     "preferred",
+			})
+		}
+
+		return true
+	})
+
+	for _, n := range strings.Split(gjson.GetBytes(body, "properties.alt_name").String(), ";") {
+
+		n = strings.TrimSpace(n)
+
+		if n != "" {
+
+			alt_names = append(alt_names, &location.AlternateName{
+				Name: n,
+				Kind: "variant",
+			})
+		}
+	}
+
+	return alt_names
+}
+
+// firstTag returns the value of the first of 'keys' which is present in the properties of 'body'.
+func firstTag(body []byte, keys ...string) string {
+
+	for _, k := range keys {
+
+		v := gjson.GetBytes(body, "properties."+k).String()
+
+		if v != "" {
+			return v
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,636 @@
+package openstreetmap
+
+// https://wiki.openstreetmap.org/wiki/PBF_Format
+// https://github.com/openstreetmap/OSM-binary/tree/master/osmpbf
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The maximum sizes of a BlobHeader and a (compressed) Blob as defined by the PBF specification.
+const (
+	max_blob_header_size = 64 * 1024
+	max_blob_size        = 32 * 1024 * 1024
+)
+
+// unsupportedCompressionError is returned (wrapped) by `readPBF` for blobs compressed with anything other than zlib.
+var unsupportedCompressionError = errors.New("Unsupported blob compression")
+
+// blob_compressions maps the field numbers of the (unsupported) compressed data fields in a Blob message to the
+// name of their compression scheme.
+var blob_compressions = map[protowire.Number]string{
+	4: "lzma",
+	5: "bzip2",
+	6: "lz4",
+	7: "zstd",
+}
+
+// The kinds of OSM elements emitted by `readPBF`.
+const (
+	NODE string = "node"
+	WAY  string = "way"
+)
+
+// element is a minimal representation of an OSM node or way.
+type element struct {
+	// The kind of element; one of NODE or WAY
+	Type string
+	Id   int64
+	// Tags is nil if the element does not have any tags
+	Tags map[string]string
+	// The element's coordinates (nodes only)
+	Lat float64
+	Lon float64
+	// The IDs of the element's nodes (ways only)
+	Refs []int64
+	// The Unix timestamp of the element's last edit, if known
+	Timestamp int64
+}
+
+// readPBFOptions defines which kinds of elements `readPBF` decodes.
+type readPBFOptions struct {
+	Nodes bool
+	Ways  bool
+}
+
+// primitiveBlock defines the block-level properties needed to decode the elements in a PrimitiveBlock message.
+type primitiveBlock struct {
+	strings          []string
+	granularity      int64
+	date_granularity int64
+	lat_offset       int64
+	lon_offset       int64
+}
+
+// field is a single decoded protocol buffer field. Depending on its wire type either 'varint' or 'bytes' is set.
+type field struct {
+	num    protowire.Number
+	typ    protowire.Type
+	varint uint64
+	bytes  []byte
+}
+
+// readPBF reads the OSM PBF data in 'r' and invokes 'cb' for each of the nodes and ways enabled by 'opts'.
+// Relations are ignored.
+func readPBF(ctx context.Context, r io.Reader, opts *readPBFOptions, cb func(*element) error) error {
+
+	br := bufio.NewReader(r)
+
+	for {
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// pass
+		}
+
+		var header_size uint32
+
+		err := binary.Read(br, binary.BigEndian, &header_size)
+
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("Failed to read blob header size, %w", err)
+		}
+
+		if header_size > max_blob_header_size {
+			return fmt.Errorf("Blob header size (%d) exceeds maximum size", header_size)
+		}
+
+		header := make([]byte, header_size)
+
+		_, err = io.ReadFull(br, header)
+
+		if err != nil {
+			return fmt.Errorf("Failed to read blob header, %w", err)
+		}
+
+		blob_type, blob_size, err := decodeBlobHeader(header)
+
+		if err != nil {
+			return fmt.Errorf("Failed to decode blob header, %w", err)
+		}
+
+		if blob_size > max_blob_size {
+			return fmt.Errorf("Blob size (%d) exceeds maximum size", blob_size)
+		}
+
+		blob := make([]byte, blob_size)
+
+		_, err = io.ReadFull(br, blob)
+
+		if err != nil {
+			return fmt.Errorf("Failed to read blob, %w", err)
+		}
+
+		// The "OSMHeader" block only contains metadata about the file
+
+		if blob_type != "OSMData" {
+			continue
+		}
+
+		data, err := decodeBlob(blob)
+
+		if err != nil {
+			return fmt.Errorf("Failed to decode blob, %w", err)
+		}
+
+		err = decodePrimitiveBlock(data, opts, cb)
+
+		if err != nil {
+			return fmt.Errorf("Failed to decode primitive block, %w", err)
+		}
+	}
+}
+
+// decodeBlobHeader returns the type and size of the blob described by the BlobHeader message 'b'.
+func decodeBlobHeader(b []byte) (string, int, error) {
+
+	blob_type := ""
+	blob_size := 0
+
+	err := walkMessage(b, func(f *field) error {
+
+		switch f.num {
+		case 1:
+			blob_type = string(f.bytes)
+		case 3:
+			blob_size = int(f.varint)
+		}
+
+		return nil
+	})
+
+	return blob_type, blob_size, err
+}
+
+// decodeBlob returns the uncompressed contents of the Blob message 'b'. Only raw and zlib-compressed blobs are supported.
+func decodeBlob(b []byte) ([]byte, error) {
+
+	var raw []byte
+	var zlib_data []byte
+	raw_size := 0
+
+	err := walkMessage(b, func(f *field) error {
+
+		switch f.num {
+		case 1:
+			raw = f.bytes
+		case 2:
+			raw_size = int(f.varint)
+		case 3:
+			zlib_data = f.bytes
+		case 4, 5, 6, 7:
+			return fmt.Errorf("%w '%s', only raw and zlib-compressed blobs can be read", unsupportedCompressionError, blob_compressions[f.num])
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if raw != nil {
+		return raw, nil
+	}
+
+	if zlib_data == nil {
+		return nil, fmt.Errorf("Blob has no data")
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(zlib_data))
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to create zlib reader, %w", err)
+	}
+
+	defer zr.Close()
+
+	buf := bytes.NewBuffer(make([]byte, 0, raw_size))
+
+	_, err = io.Copy(buf, zr)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to decompress blob, %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decodePrimitiveBlock invokes 'cb' for each of the nodes and ways, enabled by 'opts', in the PrimitiveBlock message 'b'.
+func decodePrimitiveBlock(b []byte, opts *readPBFOptions, cb func(*element) error) error {
+
+	blk := &primitiveBlock{
+		strings:          make([]string, 0),
+		granularity:      100,
+		date_granularity: 1000,
+	}
+
+	groups := make([][]byte, 0)
+
+	err := walkMessage(b, func(f *field) error {
+
+		switch f.num {
+		case 1:
+
+			return walkMessage(f.bytes, func(f *field) error {
+
+				if f.num == 1 {
+					blk.strings = append(blk.strings, string(f.bytes))
+				}
+
+				return nil
+			})
+
+		case 2:
+			groups = append(groups, f.bytes)
+		case 17:
+			blk.granularity = int64(f.varint)
+		case 18:
+			blk.date_granularity = int64(f.varint)
+		case 19:
+			blk.lat_offset = int64(f.varint)
+		case 20:
+			blk.lon_offset = int64(f.varint)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, g := range groups {
+
+		err := walkMessage(g, func(f *field) error {
+
+			switch {
+			case f.num == 1 && opts.Nodes:
+				return blk.decodeNode(f.bytes, cb)
+			case f.num == 2 && opts.Nodes:
+				return blk.decodeDenseNodes(f.bytes, cb)
+			case f.num == 3 && opts.Ways:
+				return blk.decodeWay(f.bytes, cb)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (blk *primitiveBlock) decodeNode(b []byte, cb func(*element) error) error {
+
+	el := &element{
+		Type: NODE,
+	}
+
+	keys := make([]uint64, 0)
+	vals := make([]uint64, 0)
+
+	var lat int64
+	var lon int64
+
+	err := walkMessage(b, func(f *field) error {
+
+		var err error
+
+		switch f.num {
+		case 1:
+			el.Id = protowire.DecodeZigZag(f.varint)
+		case 2:
+			keys, err = packedVarints(f, keys)
+		case 3:
+			vals, err = packedVarints(f, vals)
+		case 4:
+			el.Timestamp, err = blk.decodeInfoTimestamp(f.bytes)
+		case 8:
+			lat = protowire.DecodeZigZag(f.varint)
+		case 9:
+			lon = protowire.DecodeZigZag(f.varint)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("Failed to decode node, %w", err)
+	}
+
+	el.Tags, err = blk.tags(keys, vals)
+
+	if err != nil {
+		return fmt.Errorf("Failed to decode tags for node %d, %w", el.Id, err)
+	}
+
+	el.Lat = blk.coordinate(blk.lat_offset, lat)
+	el.Lon = blk.coordinate(blk.lon_offset, lon)
+
+	return cb(el)
+}
+
+func (blk *primitiveBlock) decodeDenseNodes(b []byte, cb func(*element) error) error {
+
+	ids := make([]uint64, 0)
+	lats := make([]uint64, 0)
+	lons := make([]uint64, 0)
+	keys_vals := make([]uint64, 0)
+	timestamps := make([]uint64, 0)
+
+	err := walkMessage(b, func(f *field) error {
+
+		var err error
+
+		switch f.num {
+		case 1:
+			ids, err = packedVarints(f, ids)
+		case 5:
+
+			err = walkMessage(f.bytes, func(f *field) error {
+
+				var err error
+
+				if f.num == 2 {
+					timestamps, err = packedVarints(f, timestamps)
+				}
+
+				return err
+			})
+
+		case 8:
+			lats, err = packedVarints(f, lats)
+		case 9:
+			lons, err = packedVarints(f, lons)
+		case 10:
+			keys_vals, err = packedVarints(f, keys_vals)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("Failed to decode dense nodes, %w", err)
+	}
+
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return fmt.Errorf("Dense nodes have mismatched IDs and coordinates")
+	}
+
+	// IDs, coordinates and timestamps are delta-encoded. Tags are encoded as a flat list of (key, value) string
+	// table indices with each node's tags terminated by a 0.
+
+	var id int64
+	var lat int64
+	var lon int64
+	var ts int64
+
+	offset := 0
+
+	for i := 0; i < len(ids); i++ {
+
+		id += protowire.DecodeZigZag(ids[i])
+		lat += protowire.DecodeZigZag(lats[i])
+		lon += protowire.DecodeZigZag(lons[i])
+
+		el := &element{
+			Type: NODE,
+			Id:   id,
+			Lat:  blk.coordinate(blk.lat_offset, lat),
+			Lon:  blk.coordinate(blk.lon_offset, lon),
+		}
+
+		if i < len(timestamps) {
+			ts += protowire.DecodeZigZag(timestamps[i])
+			el.Timestamp = ts * blk.date_granularity / 1000
+		}
+
+		for offset < len(keys_vals) {
+
+			k := keys_vals[offset]
+			offset += 1
+
+			if k == 0 {
+				break
+			}
+
+			if offset >= len(keys_vals) {
+				return fmt.Errorf("Dense node %d has a key without a value", id)
+			}
+
+			v := keys_vals[offset]
+			offset += 1
+
+			if k >= uint64(len(blk.strings)) || v >= uint64(len(blk.strings)) {
+				return fmt.Errorf("Dense node %d has an invalid string table index", id)
+			}
+
+			if el.Tags == nil {
+				el.Tags = make(map[string]string)
+			}
+
+			el.Tags[blk.strings[k]] = blk.strings[v]
+		}
+
+		err := cb(el)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (blk *primitiveBlock) decodeWay(b []byte, cb func(*element) error) error {
+
+	el := &element{
+		Type: WAY,
+	}
+
+	keys := make([]uint64, 0)
+	vals := make([]uint64, 0)
+	refs := make([]uint64, 0)
+
+	err := walkMessage(b, func(f *field) error {
+
+		var err error
+
+		switch f.num {
+		case 1:
+			el.Id = int64(f.varint)
+		case 2:
+			keys, err = packedVarints(f, keys)
+		case 3:
+			vals, err = packedVarints(f, vals)
+		case 4:
+			el.Timestamp, err = blk.decodeInfoTimestamp(f.bytes)
+		case 8:
+			refs, err = packedVarints(f, refs)
+		}
+
+		return err
+	})
+
+	if err != nil {
+		return fmt.Errorf("Failed to decode way, %w", err)
+	}
+
+	el.Tags, err = blk.tags(keys, vals)
+
+	if err != nil {
+		return fmt.Errorf("Failed to decode tags for way %d, %w", el.Id, err)
+	}
+
+	el.Refs = make([]int64, len(refs))
+
+	var ref int64
+
+	for i, v := range refs {
+		ref += protowire.DecodeZigZag(v)
+		el.Refs[i] = ref
+	}
+
+	return cb(el)
+}
+
+// decodeInfoTimestamp returns the Unix timestamp in the Info message 'b'.
+func (blk *primitiveBlock) decodeInfoTimestamp(b []byte) (int64, error) {
+
+	var ts int64
+
+	err := walkMessage(b, func(f *field) error {
+
+		if f.num == 2 {
+			ts = int64(f.varint) * blk.date_granularity / 1000
+		}
+
+		return nil
+	})
+
+	return ts, err
+}
+
+// tags returns a dictionary of tags derived from the string table indices in 'keys' and 'vals' or nil if there are no tags.
+func (blk *primitiveBlock) tags(keys []uint64, vals []uint64) (map[string]string, error) {
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	if len(keys) != len(vals) {
+		return nil, fmt.Errorf("Mismatched keys and values")
+	}
+
+	tags := make(map[string]string)
+
+	for i, k := range keys {
+
+		v := vals[i]
+
+		if k >= uint64(len(blk.strings)) || v >= uint64(len(blk.strings)) {
+			return nil, fmt.Errorf("Invalid string table index")
+		}
+
+		tags[blk.strings[k]] = blk.strings[v]
+	}
+
+	return tags, nil
+}
+
+// coordinate returns the decimal degrees for the encoded coordinate 'v'.
+func (blk *primitiveBlock) coordinate(offset int64, v int64) float64 {
+	return 1e-9 * float64(offset+(blk.granularity*v))
+}
+
+// walkMessage invokes 'cb' for each of the fields in the protocol buffer message 'b'.
+func walkMessage(b []byte, cb func(*field) error) error {
+
+	for len(b) > 0 {
+
+		num, typ, n := protowire.ConsumeTag(b)
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		b = b[n:]
+
+		f := &field{
+			num: num,
+			typ: typ,
+		}
+
+		switch typ {
+		case protowire.VarintType:
+			f.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			f.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+
+		b = b[n:]
+
+		err := cb(f)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// packedVarints appends the varint values in 'f' to 'values'. Repeated fields may be encoded either as a single
+// (packed) length-delimited field or as individual varint fields so both are supported.
+func packedVarints(f *field, values []uint64) ([]uint64, error) {
+
+	switch f.typ {
+	case protowire.VarintType:
+		return append(values, f.varint), nil
+	case protowire.BytesType:
+		// pass
+	default:
+		return nil, fmt.Errorf("Unexpected wire type for field %d", f.num)
+	}
+
+	b := f.bytes
+
+	for len(b) > 0 {
+
+		v, n := protowire.ConsumeVarint(b)
+
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+
+		values = append(values, v)
+		b = b[n:]
+	}
+
+	return values, nil
+}
@@ -26,6 +26,10 @@ type OvertureIterator struct {
 	start_after int
 }
 
+// new_parquet_iterator is the function used to create iterators for Overture Parquet files. It is assigned by
+// the (duckdb) build-tagged parquet.go file so that DuckDB is only a dependency when it is necessary.
+var new_parquet_iterator iterator.IteratorInitializationFunc
+
 func init() {
 	ctx := context.Background()
 	err := iterator.RegisterIterator(ctx, "overture", NewOvertureIterator)
@@ -44,6 +48,20 @@ func NewOvertureIterator(ctx context.Context, uri string) (iterator.Iterator, er
 
 	q := u.Query()
 
+	switch q.Get("format") {
+	case "", "geojsonl":
+		// pass
+	case "parquet":
+
+		if new_parquet_iterator == nil {
+			return nil, fmt.Errorf("Reading Parquet files requires tools be built with the -duckdb tag")
+		}
+
+		return new_parquet_iterator(ctx, uri)
+	default:
+		return nil, fmt.Errorf("Invalid ?format= parameter")
+	}
+
 	bucket_uri := q.Get("bucket-uri")
 
 	if bucket_uri == "" {
@@ -0,0 +1,339 @@
+//go:build duckdb
+
+package overture
+
+// go run -tags duckdb cmd/index-locations/main.go -verbose -location-database-uri null:// -location-parser-uri overtureplaces:// -iterator-uri 'overture://?format=parquet&country=CA&min-confidence=0.7' '/usr/local/data/overture/places/*.parquet'
+
+// https://docs.overturemaps.org/guides/places/
+// https://duckdb.org/docs/data/parquet/overview
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/marcboeker/go-duckdb"
+
+	"github.com/whosonfirst/go-dedupe/iterator"
+)
+
+// OvertureParquetIterator implements the `iterator.Iterator` interface for Overture places (Geo)Parquet files read using DuckDB.
+type OvertureParquetIterator struct {
+	iterator.Iterator
+	db          *sql.DB
+	max_workers int
+	// Only places with a confidence score greater than or equal to this value are emitted.
+	min_confidence float64
+	// Only places whose bounding box intersects this bounding box (minx, miny, maxx, maxy) are emitted.
+	bbox []float64
+	// Only places with an address in one of these countries are emitted.
+	countries []string
+}
+
+func init() {
+	new_parquet_iterator = NewOvertureParquetIterator
+}
+
+// NewOvertureParquetIterator returns a new `OvertureParquetIterator` instance configured by 'uri' which is expected to take the form of:
+//
+//	overture://?format=parquet&{PARAMETERS}
+//
+// Where {PARAMETERS} may be:
+// * `?max-workers=` The maximum number of callbacks to invoke concurrently. Default is 20.
+// * `?min-confidence=` Only emit places with a confidence score greater than or equal to this value.
+// * `?bbox=` Only emit places which intersect this comma-separated bounding box (minx,miny,maxx,maxy).
+// * `?country=` Only emit places with an address in this (ISO 3166-1 alpha-2) country. May be passed multiple times.
+func NewOvertureParquetIterator(ctx context.Context, uri string) (iterator.Iterator, error) {
+
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	max_workers := 20
+
+	if q.Has("max-workers") {
+
+		v, err := strconv.Atoi(q.Get("max-workers"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?max-workers= parameter, %w", err)
+		}
+
+		max_workers = v
+	}
+
+	min_confidence := 0.0
+
+	if q.Has("min-confidence") {
+
+		v, err := strconv.ParseFloat(q.Get("min-confidence"), 64)
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?min-confidence= parameter, %w", err)
+		}
+
+		min_confidence = v
+	}
+
+	var bbox []float64
+
+	if q.Has("bbox") {
+
+		parts := strings.Split(q.Get("bbox"), ",")
+
+		if len(parts) != 4 {
+			return nil, fmt.Errorf("Invalid ?bbox= parameter, expected minx,miny,maxx,maxy")
+		}
+
+		bbox = make([]float64, 4)
+
+		for i, p := range parts {
+
+			v, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+
+			if err != nil {
+				return nil, fmt.Errorf("Invalid ?bbox= parameter, %w", err)
+			}
+
+			bbox[i] = v
+		}
+	}
+
+	countries := make([]string, 0)
+
+	for _, c := range q["country"] {
+
+		if c != "" {
+			countries = append(countries, strings.ToUpper(c))
+		}
+	}
+
+	db, err := sql.Open("duckdb", "")
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to open database connection, %w", err)
+	}
+
+	iter := &OvertureParquetIterator{
+		db:             db,
+		max_workers:    max_workers,
+		min_confidence: min_confidence,
+		bbox:           bbox,
+		countries:      countries,
+	}
+
+	return iter, nil
+}
+
+// IterateWithCallback emits the places in one or more Overture places Parquet files, or glob patterns matching
+// Parquet files, as GeoJSON features. Each feature's geometry is a point derived from the place's "bbox" column
+// and its properties are all the other columns in the file.
+func (iter *OvertureParquetIterator) IterateWithCallback(ctx context.Context, cb iterator.IteratorCallback, uris ...string) error {
+
+	throttle := make(chan bool, iter.max_workers)
+
+	for i := 0; i < iter.max_workers; i++ {
+		throttle <- true
+	}
+
+	wg := new(sync.WaitGroup)
+
+	defer wg.Wait()
+
+	for _, path := range uris {
+
+		logger := slog.Default()
+		logger = logger.With("path", path)
+
+		q, args := iter.query(path)
+
+		logger.Debug("Query places", "query", q, "args", args)
+
+		rows, err := iter.db.QueryContext(ctx, q, args...)
+
+		if err != nil {
+			return fmt.Errorf("Failed to query %s, %w", path, err)
+		}
+
+		columns, err := rows.Columns()
+
+		if err != nil {
+			rows.Close()
+			return fmt.Errorf("Failed to derive columns for %s, %w", path, err)
+		}
+
+		for rows.Next() {
+
+			select {
+			case <-ctx.Done():
+				rows.Close()
+				return ctx.Err()
+			default:
+				// pass
+			}
+
+			// The last two columns are the (derived) longitude and latitude of the place
+
+			values := make([]interface{}, len(columns))
+			ptrs := make([]interface{}, len(columns))
+
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+
+			err := rows.Scan(ptrs...)
+
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("Failed to scan row, %w", err)
+			}
+
+			props := make(map[string]interface{})
+
+			for i, col := range columns[:len(columns)-2] {
+				props[col] = jsonValue(values[i])
+			}
+
+			f := map[string]interface{}{
+				"type": "Feature",
+				"geometry": map[string]interface{}{
+					"type":        "Point",
+					"coordinates": []interface{}{values[len(values)-2], values[len(values)-1]},
+				},
+				"properties": props,
+			}
+
+			body, err := json.Marshal(f)
+
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("Failed to marshal feature, %w", err)
+			}
+
+			<-throttle
+
+			wg.Add(1)
+
+			go func(body []byte) {
+
+				defer func() {
+					wg.Done()
+					throttle <- true
+				}()
+
+				err := cb(ctx, body)
+
+				if err != nil {
+					logger.Error("Iterator callback for record failed", "error", err)
+				}
+
+			}(body)
+		}
+
+		err = rows.Err()
+		rows.Close()
+
+		if err != nil {
+			return fmt.Errorf("Failed to iterate rows for %s, %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func (iter *OvertureParquetIterator) Close(ctx context.Context) error {
+	return iter.db.Close()
+}
+
+// query returns the SQL query, and its arguments, used to read places from 'path'. Filters are applied to the
+// Parquet read so that DuckDB can skip row groups which don't match using the file's column statistics.
+func (iter *OvertureParquetIterator) query(path string) (string, []interface{}) {
+
+	where := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	if iter.min_confidence > 0 {
+		where = append(where, "confidence >= ?")
+		args = append(args, iter.min_confidence)
+	}
+
+	if len(iter.bbox) == 4 {
+		where = append(where, "bbox.xmax >= ? AND bbox.xmin <= ? AND bbox.ymax >= ? AND bbox.ymin <= ?")
+		args = append(args, iter.bbox[0], iter.bbox[2], iter.bbox[1], iter.bbox[3])
+	}
+
+	if len(iter.countries) > 0 {
+
+		placeholders := make([]string, len(iter.countries))
+
+		for i, c := range iter.countries {
+			placeholders[i] = "list_contains(list_transform(addresses, a -> a.country), ?)"
+			args = append(args, c)
+		}
+
+		where = append(where, fmt.Sprintf("(%s)", strings.Join(placeholders, " OR ")))
+	}
+
+	// The geometry column is WKB-encoded and decoding it would require DuckDB's spatial extension. Overture
+	// places are points so their centroid is derived from the (GeoParquet) bbox column instead.
+
+	q := fmt.Sprintf("SELECT * EXCLUDE (geometry) FROM read_parquet('%s')", strings.ReplaceAll(path, "'", "''"))
+
+	if len(where) > 0 {
+		q = fmt.Sprintf("%s WHERE %s", q, strings.Join(where, " AND "))
+	}
+
+	q = fmt.Sprintf("SELECT p.*, (p.bbox.xmin + p.bbox.xmax) / 2, (p.bbox.ymin + p.bbox.ymax) / 2 FROM (%s) AS p", q)
+
+	return q, args
+}
+
+// jsonValue returns 'v', a value scanned from a DuckDB row, in a form that can be encoded as JSON. Specifically
+// DuckDB MAP values, whose keys are untyped, are converted to string-keyed maps.
+func jsonValue(v interface{}) interface{} {
+
+	switch v := v.(type) {
+	case duckdb.Map:
+
+		m := make(map[string]interface{}, len(v))
+
+		for k, mv := range v {
+			m[fmt.Sprintf("%v", k)] = jsonValue(mv)
+		}
+
+		return m
+
+	case map[string]interface{}:
+
+		m := make(map[string]interface{}, len(v))
+
+		for k, mv := range v {
+			m[k] = jsonValue(mv)
+		}
+
+		return m
+
+	case []interface{}:
+
+		l := make([]interface{}, len(v))
+
+		for i, lv := range v {
+			l[i] = jsonValue(lv)
+		}
+
+		return l
+
+	default:
+		return v
+	}
+}
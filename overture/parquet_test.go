@@ -0,0 +1,172 @@
+//go:build duckdb
+
+package overture
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/whosonfirst/go-dedupe/iterator"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+func TestOvertureParquetIterator(t *testing.T) {
+
+	ctx := context.Background()
+
+	tmp_dir, err := os.MkdirTemp("", "overture")
+
+	if err != nil {
+		t.Fatalf("Failed to create temp dir, %v", err)
+	}
+
+	defer os.RemoveAll(tmp_dir)
+
+	path := filepath.Join(tmp_dir, "places.parquet")
+
+	err = writeTestParquet(ctx, path)
+
+	if err != nil {
+		t.Fatalf("Failed to write Parquet file, %v", err)
+	}
+
+	prsr, err := location.NewParser(ctx, "overtureplaces://")
+
+	if err != nil {
+		t.Fatalf("Failed to create parser, %v", err)
+	}
+
+	tests := map[string][]string{
+		"overture://?format=parquet":                                  []string{"ovtr:id=a", "ovtr:id=b"},
+		"overture://?format=parquet&country=ca":                       []string{"ovtr:id=a"},
+		"overture://?format=parquet&min-confidence=0.9":               []string{"ovtr:id=a"},
+		"overture://?format=parquet&bbox=-75,40,-73,41":               []string{"ovtr:id=b"},
+		"overture://?format=parquet&country=CA&country=US":            []string{"ovtr:id=a", "ovtr:id=b"},
+		"overture://?format=parquet&country=US&min-confidence=0.9":    []string{},
+		"overture://?format=parquet&bbox=-74,45,-73,46&max-workers=1": []string{"ovtr:id=a"},
+	}
+
+	for iter_uri, expected := range tests {
+
+		iter, err := iterator.NewIterator(ctx, iter_uri)
+
+		if err != nil {
+			t.Fatalf("Failed to create iterator for %s, %v", iter_uri, err)
+		}
+
+		locs := make(map[string]*location.Location)
+		mu := new(sync.Mutex)
+
+		iter_cb := func(ctx context.Context, body []byte) error {
+
+			loc, err := prsr.Parse(ctx, body)
+
+			if err != nil {
+				return err
+			}
+
+			mu.Lock()
+			locs[loc.ID] = loc
+			mu.Unlock()
+
+			return nil
+		}
+
+		err = iter.IterateWithCallback(ctx, iter_cb, path)
+
+		if err != nil {
+			t.Fatalf("Failed to iterate %s, %v", iter_uri, err)
+		}
+
+		iter.Close(ctx)
+
+		if len(locs) != len(expected) {
+			t.Fatalf("Expected %d locations for %s, got %d", len(expected), iter_uri, len(locs))
+		}
+
+		for _, id := range expected {
+
+			_, exists := locs[id]
+
+			if !exists {
+				t.Fatalf("Missing %s for %s", id, iter_uri)
+			}
+		}
+	}
+
+	iter, _ := iterator.NewIterator(ctx, "overture://?format=parquet")
+	defer iter.Close(ctx)
+
+	iter_cb := func(ctx context.Context, body []byte) error {
+
+		loc, err := prsr.Parse(ctx, body)
+
+		if err != nil {
+			return err
+		}
+
+		if loc.ID != "ovtr:id=a" {
+			return nil
+		}
+
+		if loc.Name != "Cafe Olimpico" || loc.Address != "124 Rue Saint-Viateur O Montréal QC H2T 2L1 CA" {
+			return fmt.Errorf("Unexpected location: %s", loc.String())
+		}
+
+		if loc.Centroid.Lon() != -73.60033 || loc.Centroid.Lat() != 45.524115 {
+			return fmt.Errorf("Unexpected centroid: %v", loc.Centroid)
+		}
+
+		return nil
+	}
+
+	err = iter.IterateWithCallback(ctx, iter_cb, path)
+
+	if err != nil {
+		t.Fatalf("Failed to iterate, %v", err)
+	}
+}
+
+// writeTestParquet writes a Parquet file, with a subset of the Overture places schema, containing two places to 'path'.
+func writeTestParquet(ctx context.Context, path string) error {
+
+	db, err := sql.Open("duckdb", "")
+
+	if err != nil {
+		return err
+	}
+
+	defer db.Close()
+
+	q := `COPY (
+	SELECT
+		'a' AS id,
+		{'primary': 'Cafe Olimpico', 'common': MAP {'fr': 'Café Olimpico'}} AS names,
+		{'primary': 'cafe', 'alternate': ['coffee_shop']} AS categories,
+		0.95 AS confidence,
+		['https://www.cafeolimpico.com'] AS websites,
+		['+15144950746'] AS phones,
+		[{'freeform': '124 Rue Saint-Viateur O', 'locality': 'Montréal', 'postcode': 'H2T 2L1', 'region': 'QC', 'country': 'CA'}] AS addresses,
+		{'xmin': -73.60033, 'xmax': -73.60033, 'ymin': 45.524115, 'ymax': 45.524115} AS bbox,
+		'\x00'::BLOB AS geometry
+	UNION ALL
+	SELECT
+		'b' AS id,
+		{'primary': 'Joe''s Pizza', 'common': MAP {'en': 'Joe''s Pizza'}} AS names,
+		{'primary': 'pizza_restaurant', 'alternate': []} AS categories,
+		0.5 AS confidence,
+		[] AS websites,
+		[] AS phones,
+		[{'freeform': '7 Carmine St', 'locality': 'New York', 'postcode': '10014', 'region': 'NY', 'country': 'US'}] AS addresses,
+		{'xmin': -74.002, 'xmax': -74.002, 'ymin': 40.7305, 'ymax': 40.7305} AS bbox,
+		'\x00'::BLOB AS geometry
+	) TO '%s' (FORMAT PARQUET)`
+
+	_, err = db.ExecContext(ctx, fmt.Sprintf(q, path))
+	return err
+}
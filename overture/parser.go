@@ -4,17 +4,21 @@ import (
 	"context"
 	"fmt"
 	_ "log/slog"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/paulmach/orb/geojson"
 	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/category"
 	"github.com/whosonfirst/go-dedupe/location"
 )
 
 type OverturePlaceParser struct {
 	location.Parser
 	addr_keys []string
+	release   string
 }
 
 func init() {
@@ -28,6 +32,14 @@ func init() {
 
 func NewOverturePlaceParser(ctx context.Context, uri string) (location.Parser, error) {
 
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
 	addr_keys := []string{
 		"freeform",
 		"locality",
@@ -37,6 +49,7 @@ func NewOverturePlaceParser(ctx context.Context, uri string) (location.Parser, e
 
 	p := &OverturePlaceParser{
 		addr_keys: addr_keys,
+		release:   q.Get("release"),
 	}
 
 	return p, nil
@@ -61,6 +74,7 @@ func (p *OverturePlaceParser) Parse(ctx context.Context, body []byte) (*location
 	name := name_rsp.String()
 
 	addr_components := make([]string, 0)
+	var components *location.AddressComponents
 
 	addrs_rsp := gjson.GetBytes(body, "properties.addresses")
 
@@ -72,6 +86,17 @@ func (p *OverturePlaceParser) Parse(ctx context.Context, body []byte) (*location
 			addr[k] = v.String()
 		}
 
+		if components == nil {
+
+			components = &location.AddressComponents{
+				Street:   addr["freeform"],
+				Locality: addr["locality"],
+				Region:   addr["region"],
+				Postcode: addr["postcode"],
+				Country:  addr["country"],
+			}
+		}
+
 		for _, k := range p.addr_keys {
 
 			v, exists := addr[k]
@@ -86,8 +111,6 @@ func (p *OverturePlaceParser) Parse(ctx context.Context, body []byte) (*location
 		return nil, dedupe.InvalidRecord(id, fmt.Errorf("Missing 'address' properties"))
 	}
 
-	// Something something something libpostal...
-
 	addr := strings.Join(addr_components, " ")
 
 	geom_rsp := gjson.GetBytes(body, "geometry")
@@ -103,12 +126,121 @@ func (p *OverturePlaceParser) Parse(ctx context.Context, body []byte) (*location
 
 	c_id := dedupe.OvertureId(id)
 
+	if components != nil {
+		components.ParseStreet()
+	}
+
 	c := &location.Location{
-		ID:       c_id,
-		Name:     name,
-		Address:  addr,
-		Centroid: &centroid,
+		ID:                c_id,
+		Name:              name,
+		AlternateNames:    alternateNames(body),
+		Category:          category.Normalize(gjson.GetBytes(body, "properties.categories.primary").String()),
+		Address:           addr,
+		Centroid:          &centroid,
+		AddressComponents: components,
+		Provenance:        p.provenance(body),
+	}
+
+	country := ""
+
+	if components != nil {
+		country = components.Country
+	}
+
+	for _, rsp := range gjson.GetBytes(body, "properties.phones").Array() {
+		c.AddPhone(rsp.String(), country)
+	}
+
+	for _, rsp := range gjson.GetBytes(body, "properties.websites").Array() {
+		c.AddWebsite(rsp.String())
 	}
 
 	return c, nil
 }
+
+// alternateNames returns the list of `location.AlternateName` instances derived from the "names.common" and
+// "names.rules" properties in 'body'.
+func alternateNames(body []byte) []*location.AlternateName {
+
+	alt_names := make([]*location.AlternateName, 0)
+
+	common_rsp := gjson.GetBytes(body, "properties.names.common")
+
+	// Depending on the release "names.common" is either a dictionary of language codes and names
+	// or a list of { "language": ..., "value": ... } dictionaries.
+
+	if common_rsp.IsObject() {
+
+		common_rsp.ForEach(func(k gjson.Result, v gjson.Result) bool {
+
+			if v.String() != "" {
+
+				alt_names = append(alt_names, &location.AlternateName{
+					Name:     v.String(),
+					Language: k.String(),
+					Kind:     "common",
+				})
+			}
+
+			return true
+		})
+
+	} else {
+
+		for _, v := range common_rsp.Array() {
+
+			value := v.Get("value").String()
+
+			if value != "" {
+
+				alt_names = append(alt_names, &location.AlternateName{
+					Name:     value,
+					Language: v.Get("language").String(),
+					Kind:     "common",
+				})
+			}
+		}
+	}
+
+	for _, r := range gjson.GetBytes(body, "properties.names.rules").Array() {
+
+		value := r.Get("value").String()
+
+		if value == "" {
+			continue
+		}
+
+		alt_names = append(alt_names, &location.AlternateName{
+			Name:     value,
+			Language: r.Get("language").String(),
+			Kind:     r.Get("variant").String(),
+		})
+	}
+
+	return alt_names
+}
+
+// provenance returns a `location.Provenance` instance derived from the "confidence" and "sources" properties in 'body'.
+func (p *OverturePlaceParser) provenance(body []byte) *location.Provenance {
+
+	pr := &location.Provenance{
+		Source:     "overture",
+		Release:    p.release,
+		Confidence: gjson.GetBytes(body, "properties.confidence").Float(),
+	}
+
+	for _, rsp := range gjson.GetBytes(body, "properties.sources").Array() {
+
+		if pr.Dataset == "" {
+			pr.Dataset = rsp.Get("dataset").String()
+		}
+
+		t, err := time.Parse(time.RFC3339, rsp.Get("update_time").String())
+
+		if err == nil && t.Unix() > pr.LastModified {
+			pr.LastModified = t.Unix()
+		}
+	}
+
+	return pr
+}
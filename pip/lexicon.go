@@ -0,0 +1,32 @@
+package pip
+
+// placetypes is the list of Who's On First administrative placetypes which are indexed, ordered from the
+// most to the least specific.
+var placetypes = []string{
+	"microhood",
+	"neighbourhood",
+	"macrohood",
+	"borough",
+	"locality",
+	"localadmin",
+	"county",
+	"macrocounty",
+	"region",
+	"macroregion",
+	"dependency",
+	"country",
+}
+
+// placetypeRank returns the position of 'pt' in the list of administrative placetypes, where lower values are
+// more specific, or -1 if 'pt' is not an administrative placetype.
+func placetypeRank(pt string) int {
+
+	for i, p := range placetypes {
+
+		if p == pt {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,289 @@
+// Package pip provides a simple in-memory spatial index of Who's On First administrative polygons used to
+// derive the hierarchy (parent, locality, region and country) for a point.
+package pip
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/geojson"
+	"github.com/paulmach/orb/planar"
+	"github.com/tidwall/gjson"
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+// The default size, in decimal degrees, of the grid cells used to index polygons.
+const DEFAULT_CELL_SIZE float64 = 1.0
+
+// Index is an in-memory spatial index of Who's On First administrative polygons. Polygons are bucketed in to
+// a regular grid of cells, by their bounding boxes, and candidate polygons for a point are then tested using
+// a (planar) point-in-polygon check.
+type Index struct {
+	mu        *sync.RWMutex
+	features  []*feature
+	cells     map[cell][]int
+	cell_size float64
+}
+
+type cell [2]int
+
+type feature struct {
+	id        int64
+	placetype string
+	rank      int
+	hierarchy map[string]int64
+	geometry  orb.Geometry
+	bound     orb.Bound
+}
+
+// NewIndex returns a new, empty, `Index` instance using grid cells of `DEFAULT_CELL_SIZE` degrees.
+func NewIndex() *Index {
+	return NewIndexWithCellSize(DEFAULT_CELL_SIZE)
+}
+
+// NewIndexWithCellSize returns a new, empty, `Index` instance using grid cells of 'cell_size' degrees.
+func NewIndexWithCellSize(cell_size float64) *Index {
+
+	idx := &Index{
+		mu:        new(sync.RWMutex),
+		features:  make([]*feature, 0),
+		cells:     make(map[cell][]int),
+		cell_size: cell_size,
+	}
+
+	return idx
+}
+
+// AddFeature adds the Who's On First GeoJSON Feature in 'body' to the index. Features which are not (current)
+// administrative placetypes, alternate geometries or whose geometry is not a polygon or multipolygon are skipped.
+func (idx *Index) AddFeature(ctx context.Context, body []byte) error {
+
+	placetype := gjson.GetBytes(body, "properties.wof:placetype").String()
+	rank := placetypeRank(placetype)
+
+	if rank == -1 {
+		return nil
+	}
+
+	if gjson.GetBytes(body, "properties.src:alt_label").Exists() {
+		return nil
+	}
+
+	deprecated := gjson.GetBytes(body, "properties.edtf:deprecated").String()
+
+	if deprecated != "" && deprecated != "uuuu" {
+		return nil
+	}
+
+	id_rsp := gjson.GetBytes(body, "properties.wof:id")
+
+	if !id_rsp.Exists() {
+		return fmt.Errorf("Feature is missing wof:id property")
+	}
+
+	f, err := geojson.UnmarshalFeature(body)
+
+	if err != nil {
+		return fmt.Errorf("Failed to unmarshal feature %d, %w", id_rsp.Int(), err)
+	}
+
+	switch f.Geometry.(type) {
+	case orb.Polygon, orb.MultiPolygon:
+		// pass
+	default:
+		return nil
+	}
+
+	hierarchy := make(map[string]int64)
+
+	for k, v := range gjson.GetBytes(body, "properties.wof:hierarchy.0").Map() {
+		hierarchy[k] = v.Int()
+	}
+
+	idx_f := &feature{
+		id:        id_rsp.Int(),
+		placetype: placetype,
+		rank:      rank,
+		hierarchy: hierarchy,
+		geometry:  f.Geometry,
+		bound:     f.Geometry.Bound(),
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	i := len(idx.features)
+	idx.features = append(idx.features, idx_f)
+
+	min := idx.cellForPoint(idx_f.bound.Min)
+	max := idx.cellForPoint(idx_f.bound.Max)
+
+	for x := min[0]; x <= max[0]; x++ {
+		for y := min[1]; y <= max[1]; y++ {
+			c := cell{x, y}
+			idx.cells[c] = append(idx.cells[c], i)
+		}
+	}
+
+	return nil
+}
+
+// AddFeaturesFromSQL adds all the features in the "geojson" table of a Who's On First SQLite database to the index.
+func (idx *Index) AddFeaturesFromSQL(ctx context.Context, conn *sql.DB) error {
+
+	rows, err := conn.QueryContext(ctx, "SELECT body FROM geojson")
+
+	if err != nil {
+		return fmt.Errorf("Failed to query geojson table, %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+
+		var body []byte
+
+		err := rows.Scan(&body)
+
+		if err != nil {
+			return fmt.Errorf("Failed to scan row, %w", err)
+		}
+
+		err = idx.AddFeature(ctx, body)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// Count returns the number of features in the index.
+func (idx *Index) Count() int {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	return len(idx.features)
+}
+
+// Hierarchy returns the `location.Hierarchy` for 'pt' derived from the indexed features which contain it or nil
+// if there are none. The parent is the most specific feature containing 'pt'. The locality, region and country
+// are the features of that placetype containing 'pt' or, failing that, are taken from the first "wof:hierarchy"
+// property of the most specific feature which defines them.
+func (idx *Index) Hierarchy(pt orb.Point) *location.Hierarchy {
+
+	candidates := idx.contains(pt)
+
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	h := &location.Hierarchy{}
+
+	var parent *feature
+
+	for _, f := range candidates {
+
+		if parent == nil || f.rank < parent.rank {
+			parent = f
+		}
+
+		switch f.placetype {
+		case "locality":
+			h.LocalityId = f.id
+		case "region":
+			h.RegionId = f.id
+		case "country":
+			h.CountryId = f.id
+		}
+	}
+
+	h.ParentId = parent.id
+
+	for _, f := range sortedByRank(candidates) {
+
+		if h.LocalityId == 0 {
+			h.LocalityId = positiveId(f.hierarchy["locality_id"])
+		}
+
+		if h.RegionId == 0 {
+			h.RegionId = positiveId(f.hierarchy["region_id"])
+		}
+
+		if h.CountryId == 0 {
+			h.CountryId = positiveId(f.hierarchy["country_id"])
+		}
+	}
+
+	return h
+}
+
+// contains returns the list of indexed features which contain 'pt'.
+func (idx *Index) contains(pt orb.Point) []*feature {
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	matches := make([]*feature, 0)
+
+	for _, i := range idx.cells[idx.cellForPoint(pt)] {
+
+		f := idx.features[i]
+
+		if !f.bound.Contains(pt) {
+			continue
+		}
+
+		switch geom := f.geometry.(type) {
+		case orb.Polygon:
+
+			if planar.PolygonContains(geom, pt) {
+				matches = append(matches, f)
+			}
+
+		case orb.MultiPolygon:
+
+			if planar.MultiPolygonContains(geom, pt) {
+				matches = append(matches, f)
+			}
+		}
+	}
+
+	return matches
+}
+
+func (idx *Index) cellForPoint(pt orb.Point) cell {
+
+	x := int(math.Floor(pt.Lon() / idx.cell_size))
+	y := int(math.Floor(pt.Lat() / idx.cell_size))
+
+	return cell{x, y}
+}
+
+func sortedByRank(features []*feature) []*feature {
+
+	sorted := make([]*feature, len(features))
+	copy(sorted, features)
+
+	slices.SortStableFunc(sorted, func(a, b *feature) int {
+		return a.rank - b.rank
+	})
+
+	return sorted
+}
+
+func positiveId(id int64) int64 {
+
+	if id < 0 {
+		return 0
+	}
+
+	return id
+}
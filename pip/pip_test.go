@@ -0,0 +1,62 @@
+package pip
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/paulmach/orb"
+)
+
+func TestIndexHierarchy(t *testing.T) {
+
+	ctx := context.Background()
+
+	feature := func(id int64, placetype string, hierarchy string, min float64, max float64) []byte {
+		return []byte(fmt.Sprintf(`{"type":"Feature","properties":{"wof:id":%d,"wof:placetype":"%s","wof:hierarchy":[%s]},"geometry":{"type":"Polygon","coordinates":[[[%f,%f],[%f,%f],[%f,%f],[%f,%f],[%f,%f]]]}}`, id, placetype, hierarchy, min, min, max, min, max, max, min, max, min, min))
+	}
+
+	features := [][]byte{
+		feature(1, "country", `{"country_id":1}`, -10.0, 10.0),
+		feature(2, "region", `{"country_id":1,"region_id":2}`, -5.0, 5.0),
+		feature(3, "neighbourhood", `{"country_id":1,"region_id":2,"locality_id":4,"neighbourhood_id":3}`, -1.0, 1.0),
+		feature(5, "venue", `{"country_id":1}`, -10.0, 10.0),
+	}
+
+	idx := NewIndex()
+
+	for _, body := range features {
+
+		err := idx.AddFeature(ctx, body)
+
+		if err != nil {
+			t.Fatalf("Failed to add feature, %v", err)
+		}
+	}
+
+	if idx.Count() != 3 {
+		t.Fatalf("Expected 3 indexed features, got %d", idx.Count())
+	}
+
+	h := idx.Hierarchy(orb.Point{0.5, 0.5})
+
+	if h == nil {
+		t.Fatalf("Expected hierarchy for point")
+	}
+
+	if h.ParentId != 3 || h.LocalityId != 4 || h.RegionId != 2 || h.CountryId != 1 {
+		t.Fatalf("Unexpected hierarchy: %v", h)
+	}
+
+	h = idx.Hierarchy(orb.Point{8.0, -8.0})
+
+	if h == nil || h.ParentId != 1 || h.RegionId != 0 || h.CountryId != 1 {
+		t.Fatalf("Unexpected hierarchy: %v", h)
+	}
+
+	h = idx.Hierarchy(orb.Point{20.0, 20.0})
+
+	if h != nil {
+		t.Fatalf("Expected no hierarchy, got %v", h)
+	}
+}
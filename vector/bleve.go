@@ -23,6 +23,8 @@ import (
 	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/blevesearch/bleve/v2"
 	"github.com/blevesearch/bleve/v2/mapping"
@@ -31,9 +33,13 @@ import (
 )
 
 type BleveDatabase struct {
-	index    bleve.Index
-	embedder embeddings.Embedder
-	tmp_file string
+	index         bleve.Index
+	embedder      embeddings.Embedder
+	text_template *template.Template
+	index_names   []string
+	// If true that location names and addresses are normalized before deriving embeddings.
+	normalize bool
+	tmp_file  string
 }
 
 type BleveDocument struct {
@@ -78,13 +84,38 @@ func NewBleveDatabase(ctx context.Context, uri string) (Database, error) {
 		return nil, fmt.Errorf("Failed to create new embedder, %w", err)
 	}
 
-	var idx bleve.Index
+	text_template, err := textTemplateFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
+	normalize := false
+
+	if q.Has("normalize") {
+
+		v, err := strconv.ParseBool(q.Get("normalize"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?normalize= parameter, %w", err)
+		}
+
+		normalize = v
+	}
+
 	db_path := u.Path
+	tmp_file := ""
 
-	db_path, tmp_file, err := EntempifyURI(db_path)
+	if strings.Contains(db_path, TMP_REPLACEMENT) {
 
-	if err != nil {
-		return nil, fmt.Errorf("Failed to entempify URI, %w", err)
+		v, p, err := EntempifyURI(db_path)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to entempify URI, %w", err)
+		}
+
+		db_path = v
+		tmp_file = p
 	}
 
 	textFieldMapping := mapping.NewTextFieldMapping()
@@ -104,9 +135,12 @@ func NewBleveDatabase(ctx context.Context, uri string) (Database, error) {
 	}
 
 	db := &BleveDatabase{
-		index:    idx,
-		embedder: embdr,
-		tmp_file: tmp_file,
+		index:         idx,
+		embedder:      embdr,
+		text_template: text_template,
+		index_names:   indexNamesFromQuery(q),
+		normalize:     normalize,
+		tmp_file:      tmp_file,
 	}
 
 	return db, nil
@@ -114,8 +148,31 @@ func NewBleveDatabase(ctx context.Context, uri string) (Database, error) {
 
 func (db *BleveDatabase) Add(ctx context.Context, loc *location.Location) error {
 
+	for _, l := range locationsToIndex(loc, db.index_names) {
+
+		err := db.addLocation(ctx, l)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *BleveDatabase) addLocation(ctx context.Context, loc *location.Location) error {
+
+	if db.normalize {
+		loc = loc.Normalize()
+	}
+
 	id := loc.ID
-	text := fmt.Sprintf("%s, %s", loc.Name, loc.Address)
+
+	text, err := loc.Text(db.text_template)
+
+	if err != nil {
+		return err
+	}
 
 	embeddings, err := db.embedder.Embeddings32(ctx, text)
 
@@ -135,7 +192,15 @@ func (db *BleveDatabase) Add(ctx context.Context, loc *location.Location) error
 
 func (db *BleveDatabase) Query(ctx context.Context, loc *location.Location) ([]*QueryResult, error) {
 
-	text := fmt.Sprintf("%s, %s", loc.Name, loc.Address)
+	if db.normalize {
+		loc = loc.Normalize()
+	}
+
+	text, err := loc.Text(db.text_template)
+
+	if err != nil {
+		return nil, err
+	}
 
 	embeddings, err := db.embedder.Embeddings32(ctx, text)
 
@@ -158,7 +223,16 @@ func (db *BleveDatabase) Query(ctx context.Context, loc *location.Location) ([]*
 	slog.Info("Q", "hits", rsp.Hits)
 
 	results := make([]*QueryResult, 0)
-	return results, nil
+	return dedupeQueryResults(results), nil
+}
+
+func (db *BleveDatabase) MeetsThreshold(ctx context.Context, qr *QueryResult, threshold float64) (bool, error) {
+
+	if float64(qr.Similarity) > threshold {
+		return false, nil
+	}
+
+	return true, nil
 }
 
 func (db *BleveDatabase) Flush(ctx context.Context) error {
@@ -167,14 +241,14 @@ func (db *BleveDatabase) Flush(ctx context.Context) error {
 
 func (db *BleveDatabase) Close(ctx context.Context) error {
 
-	err := db.idx.Close()
+	err := db.index.Close()
 
 	if err != nil {
 		return err
 	}
 
-	if tmp_file != "" {
-		err := os.Remove(tmp_file)
+	if db.tmp_file != "" {
+		err := os.Remove(db.tmp_file)
 
 		if err != nil {
 			return err
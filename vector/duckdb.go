@@ -15,6 +15,7 @@ import (
 	"log/slog"
 	"net/url"
 	"strconv"
+	"text/template"
 	"time"
 
 	_ "github.com/marcboeker/go-duckdb"
@@ -34,6 +35,12 @@ type DuckDBDatabase struct {
 	max_results int
 	// The compression type to use for embeddings. Valid options are: quantize, matroyshka, none (default)
 	compression string
+	// If true that location names and addresses are normalized before deriving embeddings.
+	normalize bool
+	// The template used to derive the text (to derive embeddings) for locations. If nil then `Location.String()` is used.
+	text_template *template.Template
+	// The list of alternate names (languages or kinds) to index in addition to a location's primary name.
+	index_names []string
 	// If true that existing records are re-indexed. If not, they are skipped and left as-is.
 	refresh      bool
 	max_distance float32
@@ -63,6 +70,7 @@ func NewDuckDBDatabase(ctx context.Context, uri string) (Database, error) {
 	max_distance := float32(5.0)
 	max_results := 10
 	refresh := false
+	normalize := false
 
 	if q.Has("dimensions") {
 
@@ -108,6 +116,23 @@ func NewDuckDBDatabase(ctx context.Context, uri string) (Database, error) {
 		refresh = v
 	}
 
+	if q.Has("normalize") {
+
+		v, err := strconv.ParseBool(q.Get("normalize"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?normalize= parameter, %w", err)
+		}
+
+		normalize = v
+	}
+
+	text_template, err := textTemplateFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
 	vec_db, err := sql.Open("duckdb", "")
 
 	if err != nil {
@@ -140,12 +165,15 @@ func NewDuckDBDatabase(ctx context.Context, uri string) (Database, error) {
 	}
 
 	db := &DuckDBDatabase{
-		vec_db:       vec_db,
-		embedder:     embdr,
-		dimensions:   dimensions,
-		max_distance: max_distance,
-		max_results:  max_results,
-		refresh:      refresh,
+		vec_db:        vec_db,
+		embedder:      embdr,
+		dimensions:    dimensions,
+		max_distance:  max_distance,
+		max_results:   max_results,
+		refresh:       refresh,
+		normalize:     normalize,
+		text_template: text_template,
+		index_names:   indexNamesFromQuery(q),
 	}
 
 	return db, nil
@@ -153,8 +181,31 @@ func NewDuckDBDatabase(ctx context.Context, uri string) (Database, error) {
 
 func (db *DuckDBDatabase) Add(ctx context.Context, loc *location.Location) error {
 
+	for _, l := range locationsToIndex(loc, db.index_names) {
+
+		err := db.addLocation(ctx, l)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *DuckDBDatabase) addLocation(ctx context.Context, loc *location.Location) error {
+
+	if db.normalize {
+		loc = loc.Normalize()
+	}
+
 	id := loc.ID
-	content := loc.String()
+
+	content, err := loc.Text(db.text_template)
+
+	if err != nil {
+		return err
+	}
 
 	v, err := db.embeddings(ctx, loc)
 
@@ -176,6 +227,10 @@ func (db *DuckDBDatabase) Add(ctx context.Context, loc *location.Location) error
 
 func (db *DuckDBDatabase) Query(ctx context.Context, loc *location.Location) ([]*QueryResult, error) {
 
+	if db.normalize {
+		loc = loc.Normalize()
+	}
+
 	results := make([]*QueryResult, 0)
 
 	v, err := db.embeddings(ctx, loc)
@@ -226,7 +281,7 @@ func (db *DuckDBDatabase) Query(ctx context.Context, loc *location.Location) ([]
 
 	slog.Debug("Query rows", "time", time.Since(t1))
 
-	return results, nil
+	return dedupeQueryResults(results), nil
 }
 
 func (db *DuckDBDatabase) MeetsThreshold(ctx context.Context, qr *QueryResult, threshold float64) (bool, error) {
@@ -248,7 +303,7 @@ func (db *DuckDBDatabase) Close(ctx context.Context) error {
 
 func (db *DuckDBDatabase) embeddings(ctx context.Context, loc *location.Location) ([]byte, error) {
 
-	q, err := loc.Embeddings32(ctx, db.embedder)
+	q, err := loc.Embeddings32WithTemplate(ctx, db.embedder, db.text_template)
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to derive query for location, %w", err)
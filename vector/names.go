@@ -0,0 +1,68 @@
+package vector
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+// indexNamesFromQuery returns the list of alternate name filters defined by the "index-names" parameter
+// in 'q'. The parameter value is a comma-separated list of languages or kinds of names (or "all") which
+// are passed to the `location.Location.AlternateNameLocations` method.
+func indexNamesFromQuery(q url.Values) []string {
+
+	index_names := make([]string, 0)
+
+	for _, v := range q["index-names"] {
+
+		for _, n := range strings.Split(v, ",") {
+
+			n = strings.TrimSpace(n)
+
+			if n != "" {
+				index_names = append(index_names, n)
+			}
+		}
+	}
+
+	return index_names
+}
+
+// locationsToIndex returns the list of locations to index for 'loc': The location itself followed by one
+// location for each of its alternate names matching 'index_names'.
+func locationsToIndex(loc *location.Location, index_names []string) []*location.Location {
+
+	locations := []*location.Location{
+		loc,
+	}
+
+	if len(index_names) == 0 {
+		return locations
+	}
+
+	return append(locations, loc.AlternateNameLocations(index_names...)...)
+}
+
+// dedupeQueryResults replaces the IDs of any results derived from alternate names with the ID of the location
+// they were derived from and removes all but the first result for any given ID. Results are otherwise returned
+// in the order they were received.
+func dedupeQueryResults(results []*QueryResult) []*QueryResult {
+
+	deduped := make([]*QueryResult, 0)
+	seen := make(map[string]bool)
+
+	for _, r := range results {
+
+		r.ID = location.BaseID(r.ID)
+
+		if seen[r.ID] {
+			continue
+		}
+
+		seen[r.ID] = true
+		deduped = append(deduped, r)
+	}
+
+	return deduped
+}
@@ -15,7 +15,8 @@ import (
 	"net/url"
 	"os"
 	"strconv"
-	// "strings"
+	"strings"
+	"text/template"
 	"time"
 
 	sqlite_vec "github.com/asg017/sqlite-vec-go-bindings/cgo"
@@ -39,6 +40,12 @@ type SQLiteDatabase struct {
 	max_results int
 	// The compression type to use for embeddings. Valid options are: quantize, matroyshka, none (default)
 	compression string
+	// If true that location names and addresses are normalized before deriving embeddings.
+	normalize bool
+	// The template used to derive the text (to derive embeddings) for locations. If nil then `Location.String()` is used.
+	text_template *template.Template
+	// The list of alternate names (languages or kinds) to index in addition to a location's primary name.
+	index_names []string
 	// If true that existing records are re-indexed. If not, they are skipped and left as-is.
 	refresh bool
 
@@ -70,11 +77,18 @@ func NewSQLiteDatabase(ctx context.Context, uri string) (Database, error) {
 
 	q := u.Query()
 	dsn := q.Get("dsn")
+	tmp_path := ""
 
-	dsn, tmp_path, err := EntempifyURI(dsn)
+	if strings.Contains(dsn, TMP_REPLACEMENT) {
 
-	if err != nil {
-		return nil, fmt.Errorf("Failed to entempify DSN, %w", err)
+		v, p, err := EntempifyURI(dsn)
+
+		if err != nil {
+			return nil, fmt.Errorf("Failed to entempify DSN, %w", err)
+		}
+
+		dsn = v
+		tmp_path = p
 	}
 
 	/*
@@ -115,6 +129,7 @@ func NewSQLiteDatabase(ctx context.Context, uri string) (Database, error) {
 	max_results := 10
 	compression := "none"
 	refresh := false
+	normalize := false
 
 	if q.Has("dimensions") {
 
@@ -164,6 +179,23 @@ func NewSQLiteDatabase(ctx context.Context, uri string) (Database, error) {
 		refresh = v
 	}
 
+	if q.Has("normalize") {
+
+		v, err := strconv.ParseBool(q.Get("normalize"))
+
+		if err != nil {
+			return nil, fmt.Errorf("Invalid ?normalize= parameter, %w", err)
+		}
+
+		normalize = v
+	}
+
+	text_template, err := textTemplateFromQuery(q)
+
+	if err != nil {
+		return nil, err
+	}
+
 	if snowflake_node == nil {
 
 		n, err := snowflake.NewNode(1)
@@ -247,14 +279,17 @@ func NewSQLiteDatabase(ctx context.Context, uri string) (Database, error) {
 	}
 
 	db := &SQLiteDatabase{
-		vec_db:       vec_db,
-		embedder:     embdr,
-		dimensions:   dimensions,
-		max_distance: max_distance,
-		max_results:  max_results,
-		compression:  compression,
-		refresh:      refresh,
-		tmp_path:     tmp_path,
+		vec_db:        vec_db,
+		embedder:      embdr,
+		dimensions:    dimensions,
+		max_distance:  max_distance,
+		max_results:   max_results,
+		compression:   compression,
+		refresh:       refresh,
+		normalize:     normalize,
+		text_template: text_template,
+		index_names:   indexNamesFromQuery(q),
+		tmp_path:      tmp_path,
 	}
 
 	return db, nil
@@ -262,6 +297,24 @@ func NewSQLiteDatabase(ctx context.Context, uri string) (Database, error) {
 
 func (db *SQLiteDatabase) Add(ctx context.Context, loc *location.Location) error {
 
+	for _, l := range locationsToIndex(loc, db.index_names) {
+
+		err := db.addLocation(ctx, l)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (db *SQLiteDatabase) addLocation(ctx context.Context, loc *location.Location) error {
+
+	if db.normalize {
+		loc = loc.Normalize()
+	}
+
 	id := loc.ID
 
 	snowflake_id, err := db.getSnowflakeId(ctx, loc)
@@ -367,6 +420,10 @@ func (db *SQLiteDatabase) Add(ctx context.Context, loc *location.Location) error
 
 func (db *SQLiteDatabase) Query(ctx context.Context, loc *location.Location) ([]*QueryResult, error) {
 
+	if db.normalize {
+		loc = loc.Normalize()
+	}
+
 	results := make([]*QueryResult, 0)
 
 	query, err := db.embeddings(ctx, loc)
@@ -434,7 +491,7 @@ func (db *SQLiteDatabase) Query(ctx context.Context, loc *location.Location) ([]
 
 	slog.Debug("Query rows", "time", time.Since(t1))
 
-	return results, nil
+	return dedupeQueryResults(results), nil
 }
 
 func (db *SQLiteDatabase) MeetsThreshold(ctx context.Context, qr *QueryResult, threshold float64) (bool, error) {
@@ -486,9 +543,15 @@ func (db *SQLiteDatabase) getSnowflakeId(ctx context.Context, loc *location.Loca
 		new_id := snowflake_node.Generate()
 		snowflake_id = new_id.Int64()
 
+		content, err := loc.Text(db.text_template)
+
+		if err != nil {
+			return 0, err
+		}
+
 		q := "INSERT INTO vec_meta (id, snowflake_id, content) VALUES(?, ?, ?)"
 
-		_, err := db.vec_db.ExecContext(ctx, q, loc.ID, snowflake_id, loc.String())
+		_, err = db.vec_db.ExecContext(ctx, q, loc.ID, snowflake_id, content)
 
 		if err != nil {
 			return 0, fmt.Errorf("Failed to create entry for snowflake ID, %w", err)
@@ -522,7 +585,7 @@ func (db *SQLiteDatabase) getLocationData(ctx context.Context, snowflake_id int6
 
 func (db *SQLiteDatabase) embeddings(ctx context.Context, loc *location.Location) ([]byte, error) {
 
-	q, err := loc.Embeddings32(ctx, db.embedder)
+	q, err := loc.Embeddings32WithTemplate(ctx, db.embedder, db.text_template)
 
 	if err != nil {
 		return nil, fmt.Errorf("Failed to derive query for location, %w", err)
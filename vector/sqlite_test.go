@@ -1,3 +1,5 @@
+//go:build sqlite_vec
+
 package vector
 
 import (
@@ -0,0 +1,27 @@
+package vector
+
+import (
+	"fmt"
+	"net/url"
+	"text/template"
+
+	"github.com/whosonfirst/go-dedupe/location"
+)
+
+// textTemplateFromQuery returns a new `text/template.Template` instance derived from the "text-template"
+// parameter in 'q' for use with the `location.Location.Text` method. If the parameter is not present
+// then a nil value is returned and locations will use their default text representation.
+func textTemplateFromQuery(q url.Values) (*template.Template, error) {
+
+	if !q.Has("text-template") {
+		return nil, nil
+	}
+
+	t, err := location.NewTextTemplate(q.Get("text-template"))
+
+	if err != nil {
+		return nil, fmt.Errorf("Invalid ?text-template= parameter, %w", err)
+	}
+
+	return t, nil
+}
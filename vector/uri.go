@@ -9,6 +9,8 @@ import (
 
 const TMP_REPLACEMENT string = "{tmp}"
 
+// EntempifyURI replaces the "{tmp}" string in the path of 'uri' with a newly created temporary file, returning
+// the updated URI and the path of the temporary file. It is an error if 'uri' does not contain "{tmp}".
 func EntempifyURI(uri string) (string, string, error) {
 
 	u, err := url.Parse(uri)
@@ -21,7 +23,7 @@ func EntempifyURI(uri string) (string, string, error) {
 	q := u.Query()
 
 	if !strings.Contains(p, TMP_REPLACEMENT) {
-		return "", "", nil // fmt.Errorf("URI is missing %s string", TMP_REPLACEMENT)
+		return "", "", fmt.Errorf("URI is missing %s string", TMP_REPLACEMENT)
 	}
 
 	suffix := strings.Replace(p, TMP_REPLACEMENT, "*-", 1)
@@ -2,16 +2,25 @@ package whosonfirst
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"regexp"
 	"strconv"
 
 	"github.com/tidwall/gjson"
 	"github.com/whosonfirst/go-dedupe"
+	"github.com/whosonfirst/go-dedupe/address"
+	"github.com/whosonfirst/go-dedupe/category"
 	"github.com/whosonfirst/go-dedupe/location"
 	"github.com/whosonfirst/go-whosonfirst-feature/properties"
 )
 
+// re_name matches WOF name properties like "name:fra_x_preferred" capturing the language and kind of name.
+var re_name = regexp.MustCompile(`^name:(.+)_x_([a-z]+)$`)
+
 type WhosOnFirstVenueParser struct {
 	location.Parser
+	release string
 }
 
 func init() {
@@ -25,7 +34,17 @@ func init() {
 
 func NewWhosOnFirstVenueParser(ctx context.Context, uri string) (location.Parser, error) {
 
-	p := &WhosOnFirstVenueParser{}
+	u, err := url.Parse(uri)
+
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse URI, %w", err)
+	}
+
+	q := u.Query()
+
+	p := &WhosOnFirstVenueParser{
+		release: q.Get("release"),
+	}
 
 	return p, nil
 }
@@ -51,6 +70,39 @@ func (p *WhosOnFirstVenueParser) Parse(ctx context.Context, body []byte) (*locat
 	country := properties.Country(body)
 	metadata["country"] = country
 
+	components := &location.AddressComponents{
+		HouseNumber: gjson.GetBytes(body, "properties.addr:housenumber").String(),
+		Street:      gjson.GetBytes(body, "properties.addr:street").String(),
+		Unit:        gjson.GetBytes(body, "properties.addr:unit").String(),
+		Locality:    gjson.GetBytes(body, "properties.addr:city").String(),
+		Region:      gjson.GetBytes(body, "properties.addr:state").String(),
+		Postcode:    gjson.GetBytes(body, "properties.addr:postcode").String(),
+		Country:     country,
+	}
+
+	if components.Street == "" && addr_rsp.String() != "" {
+
+		a := address.Parse(addr_rsp.String())
+
+		components.HouseNumber = a.HouseNumber
+		components.Street = a.Street
+		components.Unit = a.Unit
+
+		if components.Locality == "" {
+			components.Locality = a.Locality
+		}
+
+		if components.Region == "" {
+			components.Region = a.Region
+		}
+
+		if components.Postcode == "" {
+			components.Postcode = a.Postcode
+		}
+	}
+
+	components.ParseStreet()
+
 	centroid, _, err := properties.Centroid(body)
 
 	if err != nil {
@@ -61,11 +113,132 @@ func (p *WhosOnFirstVenueParser) Parse(ctx context.Context, body []byte) (*locat
 	c_id := dedupe.WhosOnFirstId(str_id)
 
 	c := &location.Location{
-		ID:       c_id,
-		Name:     name,
-		Address:  addr_rsp.String(),
-		Centroid: centroid,
+		ID:                c_id,
+		Name:              name,
+		AlternateNames:    alternateNames(body),
+		Category:          venueCategory(body),
+		Address:           addr_rsp.String(),
+		Centroid:          centroid,
+		AddressComponents: components,
+		Concordances:      concordances(body),
+		Hierarchy:         hierarchy(body),
+		Provenance: &location.Provenance{
+			Source:         "whosonfirst",
+			Dataset:        gjson.GetBytes(body, "properties.wof:repo").String(),
+			Release:        p.release,
+			LastModified:   properties.LastModified(body),
+			GeometrySource: gjson.GetBytes(body, "properties.src:geom").String(),
+		},
 	}
 
+	c.AddPhone(gjson.GetBytes(body, "properties.addr:phone").String(), country)
+
 	return c, nil
 }
+
+// alternateNames returns the list of `location.AlternateName` instances derived from the "name:{LANG}_x_{KIND}"
+// properties in 'body'.
+func alternateNames(body []byte) []*location.AlternateName {
+
+	alt_names := make([]*location.AlternateName, 0)
+
+	props_rsp := gjson.GetBytes(body, "properties")
+
+	props_rsp.ForEach(func(k gjson.Result, v gjson.Result) bool {
+
+		m := re_name.FindStringSubmatch(k.String())
+
+		if len(m) != 3 {
+			return true
+		}
+
+		for _, n := range v.Array() {
+
+			if n.String() == "" {
+				continue
+			}
+
+			alt_names = append(alt_names, &location.AlternateName{
+				Name:     n.String(),
+				Language: m[1],
+				Kind:     m[2],
+			})
+		}
+
+		return true
+	})
+
+	return alt_names
+}
+
+// venueCategory returns the normalized category derived from the "sg:classifiers" properties in 'body' or, failing that,
+// its placetype.
+func venueCategory(body []byte) string {
+
+	for _, cl := range gjson.GetBytes(body, "properties.sg:classifiers").Array() {
+
+		for _, k := range []string{"subcategory", "category", "type"} {
+
+			c := category.Normalize(cl.Get(k).String())
+
+			if c != category.UNKNOWN {
+				return c
+			}
+		}
+	}
+
+	return category.Normalize(gjson.GetBytes(body, "properties.wof:placetype").String())
+}
+
+// hierarchy returns a `location.Hierarchy` instance derived from the "wof:parent_id" property and the first
+// "wof:hierarchy" in 'body' or nil if neither are present. Negative (unknown) IDs are ignored.
+func hierarchy(body []byte) *location.Hierarchy {
+
+	h := &location.Hierarchy{}
+
+	parent_id, err := properties.ParentId(body)
+
+	if err == nil && parent_id > 0 {
+		h.ParentId = parent_id
+	}
+
+	hierarchies := properties.Hierarchies(body)
+
+	if len(hierarchies) > 0 {
+
+		if id := hierarchies[0]["locality_id"]; id > 0 {
+			h.LocalityId = id
+		}
+
+		if id := hierarchies[0]["region_id"]; id > 0 {
+			h.RegionId = id
+		}
+
+		if id := hierarchies[0]["country_id"]; id > 0 {
+			h.CountryId = id
+		}
+	}
+
+	if *h == (location.Hierarchy{}) {
+		return nil
+	}
+
+	return h
+}
+
+// concordances returns the "wof:concordances" properties in 'body' as a dictionary of string values.
+func concordances(body []byte) map[string]string {
+
+	concordances := make(map[string]string)
+
+	gjson.GetBytes(body, "properties.wof:concordances").ForEach(func(k gjson.Result, v gjson.Result) bool {
+
+		if v.String() != "" {
+			concordances[k.String()] = v.String()
+		}
+
+		return true
+	})
+
+	return concordances
+}